@@ -3,11 +3,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"expense-tracker-backend/internal/config"
 	"expense-tracker-backend/internal/router"
+	"expense-tracker-backend/internal/worker"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,9 +42,18 @@ func main() {
 	}
 
 	// 5. Configurar router con todas las rutas
-	r := router.Setup(pool, cfg.JWTSecret, cfg.CORSOrigin, cfg.ResendAPIKey)
+	r, svc := router.Setup(pool, cfg.JWTSecret, cfg.CORSOrigin, cfg.ResendAPIKey, cfg.TrashRetention)
 
-	// 6. Iniciar servidor HTTP
+	// 6. Iniciar workers en segundo plano con los mismos services que usan los handlers
+	// (se detienen al cerrar el servidor)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go worker.NewRecurringWorker(svc.Recurring, cfg.WorkerInterval).Start(ctx)
+	go worker.NewTrashWorker(svc.Trash, cfg.WorkerInterval).Start(ctx)
+	go worker.NewBudgetWorker(svc.Budget, cfg.WorkerInterval).Start(ctx)
+
+	// 7. Iniciar servidor HTTP
 	log.Printf("Servidor iniciando en puerto %s...", cfg.BackendPort)
 	if err := r.Run(":" + cfg.BackendPort); err != nil {
 		log.Fatalf("Error iniciando servidor: %v", err)
@@ -0,0 +1,207 @@
+// Package classifier es un clasificador de texto Naive Bayes multinomial, pequeño y
+// en memoria, que aprende la categoría de una transacción a partir de su descripción
+// y de un rango aproximado del monto. No depende de servicios externos.
+//
+// Cada usuario tiene su propio modelo (ver Store). El modelo se entrena con las
+// transacciones del usuario y se actualiza de forma incremental con Add y Remove.
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"expense-tracker-backend/internal/textutil"
+)
+
+// Sample es una transacción ya categorizada usada para entrenar.
+type Sample struct {
+	CategoryID  string
+	Description string
+	Amount      float64
+}
+
+// Prediction es una categoría sugerida con su confianza (0 a 1).
+type Prediction struct {
+	CategoryID string  `json:"category_id"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Model guarda los conteos del clasificador. Es seguro para uso concurrente.
+type Model struct {
+	mu sync.RWMutex
+
+	docs        map[string]int            // Transacciones por categoría
+	features    map[string]map[string]int // Conteo de cada feature por categoría
+	totalFeats  map[string]int            // Total de features por categoría
+	vocabulary  map[string]int            // Apariciones de cada feature en todas las categorías
+	totalSample int
+}
+
+// NewModel crea un modelo vacío.
+func NewModel() *Model {
+	return &Model{
+		docs:       make(map[string]int),
+		features:   make(map[string]map[string]int),
+		totalFeats: make(map[string]int),
+		vocabulary: make(map[string]int),
+	}
+}
+
+// Train crea un modelo con todas las muestras.
+func Train(samples []Sample) *Model {
+	m := NewModel()
+	for _, s := range samples {
+		m.Add(s)
+	}
+	return m
+}
+
+// Size devuelve cuántas muestras tiene el modelo.
+func (m *Model) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.totalSample
+}
+
+// Add suma una muestra al modelo.
+func (m *Model) Add(s Sample) {
+	m.update(s, 1)
+}
+
+// Remove resta una muestra que se había agregado (al borrar o recategorizar).
+func (m *Model) Remove(s Sample) {
+	m.update(s, -1)
+}
+
+func (m *Model) update(s Sample, delta int) {
+	if s.CategoryID == "" {
+		return
+	}
+	feats := Features(s.Description, s.Amount)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if delta < 0 && m.docs[s.CategoryID] == 0 {
+		return // Nunca se agregó: no hay nada que restar
+	}
+
+	m.docs[s.CategoryID] += delta
+	m.totalSample += delta
+	counts := m.features[s.CategoryID]
+	if counts == nil {
+		counts = make(map[string]int)
+		m.features[s.CategoryID] = counts
+	}
+	for _, f := range feats {
+		counts[f] += delta
+		m.totalFeats[s.CategoryID] += delta
+		m.vocabulary[f] += delta
+		if counts[f] <= 0 {
+			delete(counts, f)
+		}
+		if m.vocabulary[f] <= 0 {
+			delete(m.vocabulary, f)
+		}
+	}
+	if m.docs[s.CategoryID] <= 0 {
+		delete(m.docs, s.CategoryID)
+		delete(m.features, s.CategoryID)
+		delete(m.totalFeats, s.CategoryID)
+	}
+}
+
+// Predict devuelve las categorías más probables para una descripción y monto,
+// ordenadas de mayor a menor confianza. allowed (opcional) limita las categorías
+// candidatas (ej: solo las de tipo gasto). limit <= 0 devuelve todas.
+func (m *Model) Predict(description string, amount float64, allowed map[string]bool, limit int) []Prediction {
+	feats := Features(description, amount)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.totalSample == 0 {
+		return nil
+	}
+
+	vocab := float64(len(m.vocabulary) + 1)
+	type score struct {
+		id  string
+		log float64
+	}
+	var scores []score
+	for catID, docs := range m.docs {
+		if allowed != nil && !allowed[catID] {
+			continue
+		}
+		// log P(c) + Σ log P(f|c) con suavizado de Laplace
+		logProb := math.Log(float64(docs) / float64(m.totalSample))
+		denom := float64(m.totalFeats[catID]) + vocab
+		counts := m.features[catID]
+		for _, f := range feats {
+			logProb += math.Log((float64(counts[f]) + 1) / denom)
+		}
+		scores = append(scores, score{catID, logProb})
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	// Convertir log-probabilidades en confianzas que suman 1 (softmax estable)
+	maxLog := scores[0].log
+	for _, s := range scores {
+		maxLog = math.Max(maxLog, s.log)
+	}
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s.log - maxLog)
+	}
+
+	predictions := make([]Prediction, len(scores))
+	for i, s := range scores {
+		predictions[i] = Prediction{
+			CategoryID: s.id,
+			Confidence: math.Round(math.Exp(s.log-maxLog)/sum*1000) / 1000,
+		}
+	}
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Confidence != predictions[j].Confidence {
+			return predictions[i].Confidence > predictions[j].Confidence
+		}
+		return predictions[i].CategoryID < predictions[j].CategoryID
+	})
+	if limit > 0 && len(predictions) > limit {
+		predictions = predictions[:limit]
+	}
+	return predictions
+}
+
+// Features convierte una transacción en sus features: las palabras normalizadas de la
+// descripción (de 2 o más caracteres, sin números largos como referencias) y un rango
+// de monto en escala logarítmica (medio orden de magnitud por rango).
+func Features(description string, amount float64) []string {
+	var feats []string
+	for _, w := range strings.Fields(textutil.Normalize(description)) {
+		if len(w) < 2 || (len(w) > 4 && isDigits(w)) {
+			continue
+		}
+		feats = append(feats, w)
+	}
+	if amount > 0 {
+		bucket := int(math.Floor(math.Log10(amount) * 2))
+		feats = append(feats, "amt:"+strconv.Itoa(bucket))
+	}
+	return feats
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
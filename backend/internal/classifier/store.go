@@ -0,0 +1,107 @@
+package classifier
+
+import (
+	"sync"
+	"time"
+)
+
+// Loader lee las muestras de entrenamiento de un usuario.
+type Loader func(userID string) ([]Sample, error)
+
+// Store guarda un modelo por usuario. Los modelos se entrenan la primera vez que se
+// piden y después se actualizan de forma incremental. Como red de seguridad (cambios
+// hechos por otros procesos, como el worker de recurrentes) se reentrenan cuando
+// tienen más de maxAge. Los modelos vencidos se descartan aunque el usuario no vuelva a
+// pedir sugerencias, para que la memoria no crezca con cada usuario que alguna vez las usó.
+type Store struct {
+	mu        sync.Mutex
+	models    map[string]*entry
+	load      Loader
+	maxAge    time.Duration
+	lastSweep time.Time
+}
+
+type entry struct {
+	model     *Model
+	trainedAt time.Time
+}
+
+// NewStore crea un store que entrena con load y reentrena cada maxAge.
+func NewStore(load Loader, maxAge time.Duration) *Store {
+	return &Store{
+		models:    make(map[string]*entry),
+		load:      load,
+		maxAge:    maxAge,
+		lastSweep: time.Now(),
+	}
+}
+
+// Get devuelve el modelo del usuario, entrenándolo si no existe o está vencido.
+func (s *Store) Get(userID string) (*Model, error) {
+	s.mu.Lock()
+	s.evictExpired()
+	e, ok := s.models[userID]
+	s.mu.Unlock()
+	if ok && time.Since(e.trainedAt) < s.maxAge {
+		return e.model, nil
+	}
+
+	// Entrenar fuera del lock: la consulta a la base de datos puede tardar
+	samples, err := s.load(userID)
+	if err != nil {
+		return nil, err
+	}
+	model := Train(samples)
+
+	s.mu.Lock()
+	s.models[userID] = &entry{model: model, trainedAt: time.Now()}
+	s.mu.Unlock()
+	return model, nil
+}
+
+// Add suma una muestra al modelo del usuario si ya está cargado.
+// Si no está cargado no hace nada: se entrenará completo cuando se pida.
+func (s *Store) Add(userID string, sample Sample) {
+	if m := s.loaded(userID); m != nil {
+		m.Add(sample)
+	}
+}
+
+// Remove resta una muestra del modelo del usuario si ya está cargado.
+func (s *Store) Remove(userID string, sample Sample) {
+	if m := s.loaded(userID); m != nil {
+		m.Remove(sample)
+	}
+}
+
+// Invalidate descarta el modelo del usuario (tras cambios masivos como imports
+// o reglas aplicadas). Se reentrena en la próxima sugerencia.
+func (s *Store) Invalidate(userID string) {
+	s.mu.Lock()
+	delete(s.models, userID)
+	s.mu.Unlock()
+}
+
+// evictExpired descarta los modelos de más de maxAge. Recorre el mapa como mucho una vez
+// cada maxAge. Se llama con s.mu tomado.
+func (s *Store) evictExpired() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < s.maxAge {
+		return
+	}
+	for userID, e := range s.models {
+		if now.Sub(e.trainedAt) >= s.maxAge {
+			delete(s.models, userID)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *Store) loaded(userID string) *Model {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.models[userID]; ok {
+		return e.model
+	}
+	return nil
+}
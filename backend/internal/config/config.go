@@ -6,6 +6,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config contiene toda la configuración de la aplicación.
@@ -30,6 +32,12 @@ type Config struct {
 
 	// Resend: API key para enviar emails (OTP password reset)
 	ResendAPIKey string
+
+	// Cada cuánto corren los workers en segundo plano (recurrentes, etc.)
+	WorkerInterval time.Duration
+
+	// Cuánto tiempo se conserva lo borrado en la papelera antes de eliminarlo
+	TrashRetention time.Duration
 }
 
 // Load lee todas las variables de entorno y devuelve un Config.
@@ -57,6 +65,20 @@ func Load() (*Config, error) {
 		ResendAPIKey: getEnv("RESEND_API_KEY", ""),
 	}
 
+	// Intervalo de los workers en minutos (por defecto cada hora)
+	minutes, err := strconv.Atoi(getEnv("WORKER_INTERVAL_MINUTES", "60"))
+	if err != nil || minutes < 1 {
+		return nil, fmt.Errorf("WORKER_INTERVAL_MINUTES debe ser un número entero positivo")
+	}
+	cfg.WorkerInterval = time.Duration(minutes) * time.Minute
+
+	// Días que lo borrado queda en la papelera (por defecto 30)
+	days, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || days < 1 {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS debe ser un número entero positivo")
+	}
+	cfg.TrashRetention = time.Duration(days) * 24 * time.Hour
+
 	// En producción, DATABASE_URL reemplaza las variables individuales
 	// así que solo validamos POSTGRES_PASSWORD si no hay DATABASE_URL
 	if cfg.DatabaseURL == "" && cfg.PostgresPassword == "" {
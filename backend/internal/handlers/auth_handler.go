@@ -4,6 +4,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -24,7 +25,7 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 }
 
 // Register maneja POST /api/auth/register
-// El frontend envía: { email, password, name }
+// El frontend envía: { email, password, name, presets?, language? }
 // El backend responde: { token, user }
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
@@ -41,6 +42,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Llamar al service que contiene la lógica de negocio
 	response, err := h.authService.Register(c.Request.Context(), req)
+	if errors.Is(err, services.ErrPresetNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusConflict, gin.H{
 			"error":   "registro_fallido",
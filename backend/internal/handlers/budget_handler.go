@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
 	"expense-tracker-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +21,8 @@ func NewBudgetHandler(budgetService *services.BudgetService) *BudgetHandler {
 	return &BudgetHandler{budgetService: budgetService}
 }
 
-// Create maneja POST /api/budgets - Crea o actualiza un presupuesto mensual
+// Create maneja POST /api/budgets - Crea o actualiza el presupuesto de una categoría
+// para un periodo (por defecto mensual)
 func (h *BudgetHandler) Create(c *gin.Context) {
 	userID := c.GetString("user_id")
 
@@ -32,6 +36,20 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 	}
 
 	budget, err := h.budgetService.Upsert(c.Request.Context(), userID, req)
+	if errors.Is(err, services.ErrInvalidBudgetPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_creando",
@@ -43,29 +61,47 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, budget)
 }
 
-// GetByPeriod maneja GET /api/budgets?month=2&year=2026
+// GetByPeriod maneja GET /api/budgets?date=2026-02-14 o GET /api/budgets?month=2&year=2026
+// Con date devuelve los presupuestos activos ese día, de cualquier tipo de periodo
+// (semanal, mensual, trimestral, anual o personalizado); con month y year, los activos
+// en algún día de ese mes.
 func (h *BudgetHandler) GetByPeriod(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	month, err := strconv.Atoi(c.Query("month"))
-	if err != nil || month < 1 || month > 12 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "parametro_invalido",
-			"message": "El mes debe ser un número entre 1 y 12",
-		})
-		return
-	}
+	var from, to time.Time
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "parametro_invalido",
+				"message": "La fecha debe tener formato YYYY-MM-DD",
+			})
+			return
+		}
+		from, to = date, date
+	} else {
+		month, err := strconv.Atoi(c.Query("month"))
+		if err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "parametro_invalido",
+				"message": "El mes debe ser un número entre 1 y 12 (o usa date=YYYY-MM-DD)",
+			})
+			return
+		}
 
-	year, err := strconv.Atoi(c.Query("year"))
-	if err != nil || year < 2020 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "parametro_invalido",
-			"message": "El año debe ser un número válido (>= 2020)",
-		})
-		return
+		year, err := strconv.Atoi(c.Query("year"))
+		if err != nil || year < 2020 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "parametro_invalido",
+				"message": "El año debe ser un número válido (>= 2020)",
+			})
+			return
+		}
+		from = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, -1)
 	}
 
-	budgets, err := h.budgetService.GetByPeriod(c.Request.Context(), userID, month, year)
+	budgets, err := h.budgetService.GetByPeriod(c.Request.Context(), userID, from, to)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_servidor",
@@ -93,3 +129,156 @@ func (h *BudgetHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Presupuesto eliminado exitosamente"})
 }
+
+// Copy maneja POST /api/budgets/copy
+// Copia los presupuestos de un periodo a otro, con un ajuste opcional en porcentaje.
+func (h *BudgetHandler) Copy(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CopyBudgetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.budgetService.Copy(c.Request.Context(), userID, req)
+	switch {
+	case errors.Is(err, services.ErrSameBudgetPeriod), errors.Is(err, services.ErrInvalidBudgetPeriod):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	case errors.Is(err, services.ErrNoBudgetsToCopy):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrado",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_creando",
+			"message": "Error copiando presupuestos: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTemplates maneja GET /api/budgets/templates
+func (h *BudgetHandler) GetTemplates(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	templates, err := h.budgetService.GetTemplates(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo plantillas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// CreateTemplate maneja POST /api/budgets/templates
+func (h *BudgetHandler) CreateTemplate(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateBudgetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	template, err := h.budgetService.CreateTemplate(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_creando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplate maneja PUT /api/budgets/templates/:id
+func (h *BudgetHandler) UpdateTemplate(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.UpdateBudgetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	template, err := h.budgetService.UpdateTemplate(c.Request.Context(), c.Param("id"), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_actualizando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate maneja DELETE /api/budgets/templates/:id
+func (h *BudgetHandler) DeleteTemplate(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.budgetService.DeleteTemplate(c.Request.Context(), c.Param("id"), userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Plantilla eliminada"})
+}
+
+// ApplyTemplate maneja POST /api/budgets/templates/:id/apply
+// Crea los presupuestos de la plantilla, cada uno en el periodo de su tipo que contiene la fecha indicada.
+func (h *BudgetHandler) ApplyTemplate(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.ApplyBudgetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.budgetService.ApplyTemplate(c.Request.Context(), c.Param("id"), userID, req)
+	if errors.Is(err, services.ErrInvalidBudgetPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
 	"expense-tracker-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,11 +20,15 @@ func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHand
 }
 
 // GetAll maneja GET /api/categories
-// Devuelve todas las categorías del usuario autenticado.
+// Devuelve las categorías del usuario autenticado. Las archivadas solo con
+// ?include_archived=true (para filtros y reportes históricos, no para elegir categoría).
+// Con ?tree=true las subcategorías vienen anidadas en children de su categoría padre.
 func (h *CategoryHandler) GetAll(c *gin.Context) {
 	userID := c.GetString("user_id") // Viene del middleware de auth
+	includeArchived := c.Query("include_archived") == "true"
+	tree := c.Query("tree") == "true"
 
-	categories, err := h.categoryService.GetAll(c.Request.Context(), userID)
+	categories, err := h.categoryService.GetAll(c.Request.Context(), userID, includeArchived, tree)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_servidor",
@@ -48,6 +54,13 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 	}
 
 	category, err := h.categoryService.Create(c.Request.Context(), userID, req)
+	if errors.Is(err, repository.ErrInvalidParent) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_creando",
@@ -74,6 +87,13 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	}
 
 	category, err := h.categoryService.Update(c.Request.Context(), categoryID, userID, req)
+	if errors.Is(err, repository.ErrInvalidParent) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "no_encontrada",
@@ -85,13 +105,71 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, category)
 }
 
+// Merge maneja POST /api/categories/:id/merge
+// Pasa todo lo de la categoría :id a la categoría "into" y elimina :id.
+func (h *CategoryHandler) Merge(c *gin.Context) {
+	userID := c.GetString("user_id")
+	categoryID := c.Param("id")
+
+	var req models.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := h.categoryService.Merge(c.Request.Context(), categoryID, userID, req)
+	switch {
+	case errors.Is(err, repository.ErrInvalidTargetCategory), errors.Is(err, repository.ErrCategoryTypeMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
 // Delete maneja DELETE /api/categories/:id
+// Con ?reassign_to=<id> sus transacciones, recurrentes, reglas y presupuestos pasan
+// primero a esa otra categoría del mismo tipo; sin él, solo se puede borrar si nada la usa.
 func (h *CategoryHandler) Delete(c *gin.Context) {
 	userID := c.GetString("user_id")
 	categoryID := c.Param("id")
+	reassignTo := c.Query("reassign_to")
 
-	err := h.categoryService.Delete(c.Request.Context(), categoryID, userID)
-	if err != nil {
+	if reassignTo != "" && !uuidPattern.MatchString(reassignTo) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": repository.ErrInvalidTargetCategory.Error(),
+		})
+		return
+	}
+
+	err := h.categoryService.Delete(c.Request.Context(), categoryID, userID, reassignTo)
+	switch {
+	case errors.Is(err, repository.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "categoria_en_uso",
+			"message": err.Error(),
+		})
+		return
+	case errors.Is(err, repository.ErrInvalidTargetCategory), errors.Is(err, repository.ErrCategoryTypeMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "no_encontrada",
 			"message": err.Error(),
@@ -99,5 +177,79 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Categoría eliminada exitosamente"})
+	message := "Categoría enviada a la papelera"
+	if reassignTo != "" {
+		message = "Categoría enviada a la papelera; sus movimientos pasaron a la categoría elegida"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// GetPresets maneja GET /api/categories/presets
+// Lista los paquetes de categorías con los textos en ?lang= (es o en, default es).
+func (h *CategoryHandler) GetPresets(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	presets, err := h.categoryService.GetPresets(c.Request.Context(), userID, c.Query("lang"))
+	if errors.Is(err, services.ErrUnsupportedLanguage) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo paquetes de categorías",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"presets": presets})
+}
+
+// ApplyPreset maneja POST /api/categories/presets/:id/apply
+// Crea las categorías del paquete que el usuario todavía no tiene. El idioma de los
+// nombres va en el body ({ "language": "en" }) o en ?lang=.
+func (h *CategoryHandler) ApplyPreset(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.ApplyPresetRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "datos_invalidos",
+				"message": "Verifica los datos: " + err.Error(),
+			})
+			return
+		}
+	}
+	lang := req.Language
+	if lang == "" {
+		lang = c.Query("lang")
+	}
+
+	result, err := h.categoryService.ApplyPreset(c.Request.Context(), userID, c.Param("id"), lang)
+	switch {
+	case errors.Is(err, services.ErrPresetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrado",
+			"message": err.Error(),
+		})
+		return
+	case errors.Is(err, services.ErrUnsupportedLanguage):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error aplicando paquete de categorías",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
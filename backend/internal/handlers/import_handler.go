@@ -0,0 +1,161 @@
+// Handler de importación de extractos bancarios.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize es el tamaño máximo del archivo subido (5 MB).
+const maxImportFileSize = 5 << 20
+
+type ImportHandler struct {
+	importService *services.ImportService
+}
+
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// Import maneja POST /api/transactions/import (multipart/form-data).
+// Campos:
+//   - file:         el extracto del banco (CSV, OFX o QFX)
+//   - format:       "csv" u "ofx". Vacío = según la extensión o el contenido
+//   - mapping:      JSON con models.CSVImportMapping (obligatorio para CSV, opcional para OFX)
+//   - dry_run:      "true" (por defecto) solo devuelve la vista previa; "false" guarda
+//   - skip_invalid: "true" guarda las filas válidas aunque haya filas con errores
+//   - skip_duplicates: "true" no guarda las filas que parecen duplicar una transacción existente
+func (h *ImportHandler) Import(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Debes adjuntar el archivo en el campo 'file'",
+		})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "archivo_muy_grande",
+			"message": "El archivo no puede superar 5 MB",
+		})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".ofx", ".qfx":
+			format = services.ImportFormatOFX
+		case ".csv":
+			format = services.ImportFormatCSV
+		}
+	}
+
+	var mapping models.CSVImportMapping
+	if raw := c.PostForm("mapping"); raw != "" || format == services.ImportFormatCSV {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "datos_invalidos",
+				"message": "El campo 'mapping' debe ser un JSON válido: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "No se pudo leer el archivo",
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "No se pudo leer el archivo",
+		})
+		return
+	}
+
+	dryRun := c.DefaultPostForm("dry_run", "true") != "false"
+	skipInvalid := c.PostForm("skip_invalid") == "true"
+	skipDuplicates := c.PostForm("skip_duplicates") == "true"
+
+	preview, err := h.importService.Import(c.Request.Context(), userID, format, fileHeader.Filename, data, mapping, dryRun, skipInvalid, skipDuplicates)
+	if errors.Is(err, services.ErrImportHasErrors) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "filas_invalidas",
+			"message": err.Error(),
+			"preview": preview,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_importando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun {
+		status = http.StatusCreated
+	}
+	c.JSON(status, preview)
+}
+
+// GetBatches — GET /api/imports
+func (h *ImportHandler) GetBatches(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	batches, err := h.importService.GetBatches(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo importaciones",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imports": batches})
+}
+
+// DeleteBatch — DELETE /api/imports/:id
+// Manda a la papelera todas las transacciones que creó el import.
+func (h *ImportHandler) DeleteBatch(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	deleted, err := h.importService.DeleteBatch(c.Request.Context(), id, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Importación deshecha",
+		"deleted_transactions": deleted,
+	})
+}
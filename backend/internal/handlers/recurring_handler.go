@@ -0,0 +1,102 @@
+// Handler de transacciones recurrentes — endpoints HTTP REST.
+package handlers
+
+import (
+	"net/http"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringHandler struct {
+	recurringService *services.RecurringService
+}
+
+func NewRecurringHandler(recurringService *services.RecurringService) *RecurringHandler {
+	return &RecurringHandler{recurringService: recurringService}
+}
+
+// GetAll — GET /api/recurring
+func (h *RecurringHandler) GetAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	items, err := h.recurringService.GetAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo transacciones recurrentes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recurring": items})
+}
+
+// Create — POST /api/recurring
+func (h *RecurringHandler) Create(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateRecurringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	item, err := h.recurringService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_creando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// Update — PUT /api/recurring/:id
+func (h *RecurringHandler) Update(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	var req models.UpdateRecurringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	item, err := h.recurringService.Update(c.Request.Context(), id, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_actualizando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Delete — DELETE /api/recurring/:id
+func (h *RecurringHandler) Delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if err := h.recurringService.Delete(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transacción recurrente eliminada"})
+}
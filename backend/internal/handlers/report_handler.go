@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"expense-tracker-backend/internal/services"
 
@@ -11,16 +12,25 @@ import (
 
 type ReportHandler struct {
 	reportService *services.ReportService
+	viewService   *services.ViewService
 }
 
-func NewReportHandler(reportService *services.ReportService) *ReportHandler {
-	return &ReportHandler{reportService: reportService}
+func NewReportHandler(reportService *services.ReportService, viewService *services.ViewService) *ReportHandler {
+	return &ReportHandler{reportService: reportService, viewService: viewService}
 }
 
+// Todos los reportes aceptan view_id (opcional): solo suman las transacciones de esa vista guardada.
+
 // GetMonthly maneja GET /api/reports/monthly?month=2&year=2026
+// Con rollup=true el desglose por categoría suma las subcategorías en su categoría principal.
 func (h *ReportHandler) GetMonthly(c *gin.Context) {
 	userID := c.GetString("user_id")
 
+	scope, ok := viewScope(c, h.viewService, userID)
+	if !ok {
+		return
+	}
+
 	month, err := strconv.Atoi(c.Query("month"))
 	if err != nil || month < 1 || month > 12 {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -39,7 +49,9 @@ func (h *ReportHandler) GetMonthly(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.reportService.GetMonthlySummary(c.Request.Context(), userID, month, year)
+	rollup := c.Query("rollup") == "true"
+
+	summary, err := h.reportService.GetMonthlySummary(c.Request.Context(), userID, month, year, scope, rollup)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_servidor",
@@ -55,6 +67,11 @@ func (h *ReportHandler) GetMonthly(c *gin.Context) {
 func (h *ReportHandler) GetYearly(c *gin.Context) {
 	userID := c.GetString("user_id")
 
+	scope, ok := viewScope(c, h.viewService, userID)
+	if !ok {
+		return
+	}
+
 	year, err := strconv.Atoi(c.Query("year"))
 	if err != nil || year < 2020 {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -64,7 +81,7 @@ func (h *ReportHandler) GetYearly(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.reportService.GetYearlySummary(c.Request.Context(), userID, year)
+	summary, err := h.reportService.GetYearlySummary(c.Request.Context(), userID, year, scope)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_servidor",
@@ -75,3 +92,39 @@ func (h *ReportHandler) GetYearly(c *gin.Context) {
 
 	c.JSON(http.StatusOK, summary)
 }
+
+// GetByTag maneja GET /api/reports/tags?date_from=2026-01-01&date_to=2026-12-31
+// Ambas fechas son opcionales.
+func (h *ReportHandler) GetByTag(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	scope, ok := viewScope(c, h.viewService, userID)
+	if !ok {
+		return
+	}
+
+	dateFrom, dateTo := c.Query("date_from"), c.Query("date_to")
+	for _, d := range []string{dateFrom, dateTo} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "parametro_invalido",
+				"message": "Las fechas deben tener formato YYYY-MM-DD",
+			})
+			return
+		}
+	}
+
+	report, err := h.reportService.GetTagReport(c.Request.Context(), userID, dateFrom, dateTo, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error generando reporte por etiquetas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
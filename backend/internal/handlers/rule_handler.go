@@ -0,0 +1,130 @@
+// Handler de reglas de categorización — endpoints HTTP REST.
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RuleHandler struct {
+	ruleService *services.RuleService
+}
+
+func NewRuleHandler(ruleService *services.RuleService) *RuleHandler {
+	return &RuleHandler{ruleService: ruleService}
+}
+
+// GetAll — GET /api/rules
+func (h *RuleHandler) GetAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	rules, err := h.ruleService.GetAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo reglas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// Create — POST /api/rules
+func (h *RuleHandler) Create(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.ruleService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_creando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// Update — PUT /api/rules/:id
+func (h *RuleHandler) Update(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	var req models.UpdateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.ruleService.Update(c.Request.Context(), id, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_actualizando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Delete — DELETE /api/rules/:id
+func (h *RuleHandler) Delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if err := h.ruleService.Delete(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Regla eliminada"})
+}
+
+// Apply — POST /api/rules/apply
+// Recategoriza transacciones existentes con las reglas. Por defecto es un dry run.
+func (h *RuleHandler) Apply(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.ApplyRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.ruleService.Apply(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_aplicando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
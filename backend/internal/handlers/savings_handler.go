@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"expense-tracker-backend/internal/models"
 	"expense-tracker-backend/internal/services"
@@ -133,3 +134,65 @@ func (h *SavingsHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cuenta de ahorro eliminada"})
 }
+
+// GetMovements — GET /api/savings/:id/movements?date_from=2026-01-01&date_to=2026-01-31&page=1&limit=20
+func (h *SavingsHandler) GetMovements(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := models.SavingsMovementFilter{
+		AccountID: c.Param("id"),
+		UserID:    userID,
+		DateFrom:  c.Query("date_from"),
+		DateTo:    c.Query("date_to"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	response, err := h.savingsService.GetMovements(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "error_listando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetHistory — GET /api/savings/:id/history?granularity=month
+func (h *SavingsHandler) GetHistory(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	history, err := h.savingsService.GetHistory(c.Request.Context(), id, userID, c.Query("granularity"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_historial",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetGoal — GET /api/savings/:id/goal
+func (h *SavingsHandler) GetGoal(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	goal, err := h.savingsService.GetGoal(c.Request.Context(), id, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "meta_no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
@@ -0,0 +1,72 @@
+// Handler de sugerencias de categoría.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SuggestionHandler struct {
+	suggestionService *services.SuggestionService
+}
+
+func NewSuggestionHandler(suggestionService *services.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{suggestionService: suggestionService}
+}
+
+// Suggest maneja GET /api/categories/suggest?description=uber&amount=18000&type=expense&limit=3
+// Devuelve las categorías más probables según el historial del usuario, con su confianza.
+func (h *SuggestionHandler) Suggest(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	description := c.Query("description")
+	if description == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "El parámetro 'description' es obligatorio",
+		})
+		return
+	}
+
+	var amount float64
+	if raw := c.Query("amount"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "datos_invalidos",
+				"message": "El parámetro 'amount' debe ser un número positivo",
+			})
+			return
+		}
+		amount = parsed
+	}
+
+	txType := c.Query("type")
+	if txType != "" && txType != "income" && txType != "expense" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "El parámetro 'type' debe ser income o expense",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	if limit < 1 || limit > 10 {
+		limit = 3
+	}
+
+	suggestions, err := h.suggestionService.Suggest(c.Request.Context(), userID, description, amount, txType, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error calculando sugerencias",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
@@ -0,0 +1,102 @@
+// Handler de etiquetas — endpoints HTTP REST.
+package handlers
+
+import (
+	"net/http"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// GetAll — GET /api/tags
+func (h *TagHandler) GetAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	tags, err := h.tagService.GetAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo etiquetas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// Create — POST /api/tags
+func (h *TagHandler) Create(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.tagService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_creando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// Update — PUT /api/tags/:id
+func (h *TagHandler) Update(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	var req models.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	tag, err := h.tagService.Update(c.Request.Context(), id, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_actualizando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete — DELETE /api/tags/:id
+func (h *TagHandler) Delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if err := h.tagService.Delete(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Etiqueta eliminada"})
+}
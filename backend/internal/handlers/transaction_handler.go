@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
 	"expense-tracker-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -12,30 +17,70 @@ import (
 
 type TransactionHandler struct {
 	transactionService *services.TransactionService
+	viewService        *services.ViewService
 }
 
-func NewTransactionHandler(transactionService *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{transactionService: transactionService}
+func NewTransactionHandler(transactionService *services.TransactionService, viewService *services.ViewService) *TransactionHandler {
+	return &TransactionHandler{transactionService: transactionService, viewService: viewService}
 }
 
 // GetAll maneja GET /api/transactions?type=expense&category_id=xxx&date_from=2026-01-01&date_to=2026-01-31&page=1&limit=20
+// Filtros: ver filterQuery. Orden: sort=date|amount|category|created_at y order=asc|desc.
+//
+// Paginación por cursor: enviar cursor vacío (?cursor=) para la primera página y luego
+// el next_cursor de cada respuesta. Es estable aunque se creen transacciones entre páginas
+// y no se vuelve más lenta al avanzar. En ese modo el total no se calcula salvo que se
+// pida con include_total=true; en el modo page/limit se puede omitir con include_total=false.
 func (h *TransactionHandler) GetAll(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter, ok := filterQuery(c)
+	if !ok {
+		return
+	}
+	filter.UserID = userID
 
-	filter := models.TransactionFilter{
-		UserID:     userID,
-		Type:       c.Query("type"),
-		CategoryID: c.Query("category_id"),
-		DateFrom:   c.Query("date_from"),
-		DateTo:     c.Query("date_to"),
-		Page:       page,
-		Limit:      limit,
+	listTransactions(c, h.transactionService, filter)
+}
+
+// listTransactions lee la paginación de la URL (page/limit o cursor, include_total),
+// la aplica al filtro y responde con la página de transacciones.
+func listTransactions(c *gin.Context, transactionService *services.TransactionService, filter models.TransactionFilter) {
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	cursor, keyset := c.GetQuery("cursor")
+	filter.Keyset = keyset
+	if cursor != "" {
+		after, err := models.DecodeTransactionCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "parametro_invalido",
+				"message": "El cursor no es válido: vuelve a pedir la primera página",
+			})
+			return
+		}
+		filter.After = after
 	}
 
-	response, err := h.transactionService.GetFiltered(c.Request.Context(), filter)
+	includeTotal, err := strconv.ParseBool(c.DefaultQuery("include_total", strconv.FormatBool(!keyset)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "parametro_invalido",
+			"message": "include_total debe ser true o false",
+		})
+		return
+	}
+	filter.SkipTotal = !includeTotal
+
+	response, err := transactionService.GetFiltered(c.Request.Context(), filter)
+	if errors.Is(err, services.ErrCursorSort) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "parametro_invalido",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_servidor",
@@ -61,6 +106,27 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 	}
 
 	transaction, err := h.transactionService.Create(c.Request.Context(), userID, req)
+	if errors.Is(err, services.ErrCategoryRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "categoria_requerida",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidSplits) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "divisiones_invalidas",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "error_creando",
@@ -87,6 +153,13 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 	}
 
 	transaction, err := h.transactionService.Update(c.Request.Context(), transactionID, userID, req)
+	if errors.Is(err, services.ErrInvalidSplits) || errors.Is(err, services.ErrSplitsOutdated) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "divisiones_invalidas",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "no_encontrada",
@@ -115,16 +188,64 @@ func (h *TransactionHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Transacción eliminada exitosamente"})
 }
 
+// Bulk maneja POST /api/transactions/bulk
+// Aplica delete, set_category, add_tag, set_date o set_currency a una lista de IDs o a
+// las transacciones que cumplen un filtro. Responde 200 aunque algunas fallen: el
+// resultado de cada una viene en results.
+func (h *TransactionHandler) Bulk(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.BulkTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.transactionService.Bulk(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBulk), errors.Is(err, repository.ErrBulkTooMany):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "datos_invalidos",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrBulkCategoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "no_encontrada",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "error_servidor",
+				"message": "Error aplicando la operación masiva",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // ExportCSV maneja GET /api/transactions/export
-// Devuelve un archivo CSV descargable con todas las transacciones.
+// Devuelve un archivo CSV descargable con todas las transacciones. Acepta los mismos
+// filtros y orden que GetAll, o view_id para exportar lo que muestra una vista guardada.
 func (h *TransactionHandler) ExportCSV(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	filter := models.TransactionFilter{
-		UserID:   userID,
-		DateFrom: c.Query("date_from"),
-		DateTo:   c.Query("date_to"),
+	scope, ok := viewScope(c, h.viewService, userID)
+	if !ok {
+		return
 	}
+	var filter models.TransactionFilter
+	if scope != nil {
+		filter = *scope
+	} else if filter, ok = filterQuery(c); !ok {
+		return
+	}
+	filter.UserID = userID
 
 	csv, err := h.transactionService.ExportCSV(c.Request.Context(), userID, filter)
 	if err != nil {
@@ -141,3 +262,152 @@ func (h *TransactionHandler) ExportCSV(c *gin.Context) {
 	// BOM UTF-8 para que Excel reconozca los caracteres especiales (ñ, tildes)
 	c.String(http.StatusOK, "\xEF\xBB\xBF"+csv)
 }
+
+// GetDuplicates maneja GET /api/transactions/duplicates?days=3&date_from=2026-01-01&date_to=2026-01-31
+// Devuelve los pares de transacciones que probablemente están repetidas.
+func (h *TransactionHandler) GetDuplicates(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(services.DefaultDuplicateWindowDays)))
+	if err != nil {
+		days = services.DefaultDuplicateWindowDays
+	}
+
+	pairs, err := h.transactionService.GetDuplicates(c.Request.Context(), userID, days, c.Query("date_from"), c.Query("date_to"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error buscando transacciones duplicadas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"duplicates": pairs})
+}
+
+// DismissDuplicate maneja POST /api/transactions/duplicates/dismiss
+// Marca un par como "no es duplicado" para que deje de aparecer.
+func (h *TransactionHandler) DismissDuplicate(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.DismissDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.transactionService.DismissDuplicate(c.Request.Context(), userID, req); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Par marcado como no duplicado"})
+}
+
+// filterQuery lee los filtros de transacciones de la URL. Si alguno es inválido responde
+// 400 y devuelve ok = false.
+//   - category_id y exclude_category_id se pueden repetir o ir separados por comas.
+//   - tag igual, pero se exigen todas las etiquetas.
+//   - min_amount y max_amount son inclusivos; currency es el código de 3 letras.
+//   - q busca en la descripción y la categoría, sin importar tildes ("credito" encuentra
+//     "crédito"). Acepta comillas para frases, "or" y "-" para excluir: q="pago tarjeta" -nequi
+func filterQuery(c *gin.Context) (models.TransactionFilter, bool) {
+	filter := models.TransactionFilter{
+		Type:     c.Query("type"),
+		DateFrom: c.Query("date_from"),
+		DateTo:   c.Query("date_to"),
+		Currency: strings.ToUpper(c.Query("currency")),
+		Tags:     tagsQuery(c),
+		Query:    strings.TrimSpace(c.Query("q")),
+		Sort:     c.Query("sort"),
+		Order:    strings.ToLower(c.Query("order")),
+	}
+
+	invalid := func(message string) (models.TransactionFilter, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "parametro_invalido",
+			"message": message,
+		})
+		return filter, false
+	}
+
+	var ok bool
+	if filter.CategoryIDs, ok = uuidsQuery(c, "category_id"); !ok {
+		return invalid("category_id debe ser un UUID válido")
+	}
+	if filter.ExcludeCategoryIDs, ok = uuidsQuery(c, "exclude_category_id"); !ok {
+		return invalid("exclude_category_id debe ser un UUID válido")
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **float64
+	}{{"min_amount", &filter.MinAmount}, {"max_amount", &filter.MaxAmount}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(raw, 64)
+		if err != nil || amount < 0 {
+			return invalid(param.name + " debe ser un número mayor o igual a 0")
+		}
+		*param.dest = &amount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return invalid("min_amount no puede ser mayor que max_amount")
+	}
+
+	if filter.Currency != "" && len(filter.Currency) != 3 {
+		return invalid("currency debe ser un código de 3 letras (ej: COP)")
+	}
+	if filter.Sort != "" && !slices.Contains(models.TransactionSortFields, filter.Sort) {
+		return invalid("sort debe ser uno de: " + strings.Join(models.TransactionSortFields, ", "))
+	}
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return invalid("order debe ser asc o desc")
+	}
+	if len(filter.Query) > maxSearchQueryLength {
+		return invalid("La búsqueda no puede superar 200 caracteres")
+	}
+
+	return filter, true
+}
+
+// uuidPattern valida IDs recibidos en la URL antes de enviarlos a PostgreSQL.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// uuidsQuery lee un parámetro de IDs: ?name=a&name=b o ?name=a,b
+func uuidsQuery(c *gin.Context, name string) ([]string, bool) {
+	var ids []string
+	for _, v := range c.QueryArray(name) {
+		for _, id := range strings.Split(v, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			if !uuidPattern.MatchString(id) {
+				return nil, false
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// tagsQuery lee el filtro de etiquetas: ?tag=a&tag=b o ?tag=a,b
+func tagsQuery(c *gin.Context) []string {
+	var tags []string
+	for _, v := range c.QueryArray("tag") {
+		tags = append(tags, strings.Split(v, ",")...)
+	}
+	return models.NormalizeTagNames(tags)
+}
+
+// maxSearchQueryLength limita el largo del parámetro q.
+const maxSearchQueryLength = 200
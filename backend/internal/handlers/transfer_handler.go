@@ -0,0 +1,60 @@
+// Handler de transferencias — endpoints HTTP REST.
+package handlers
+
+import (
+	"net/http"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferService *services.TransferService
+}
+
+func NewTransferHandler(transferService *services.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+// Create — POST /api/transfers
+func (h *TransferHandler) Create(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	transfer, err := h.transferService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_transfiriendo",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// Delete — DELETE /api/transfers/:id (revierte la transferencia)
+func (h *TransferHandler) Delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if err := h.transferService.Delete(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_revirtiendo",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transferencia revertida exitosamente"})
+}
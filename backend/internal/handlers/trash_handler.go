@@ -0,0 +1,74 @@
+// Handler de la papelera — endpoints HTTP REST.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"expense-tracker-backend/internal/repository"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// GetAll — GET /api/trash
+func (h *TrashHandler) GetAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	items, err := h.trashService.GetAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo la papelera",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Restore — POST /api/trash/:type/:id/restore
+func (h *TrashHandler) Restore(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if !uuidPattern.MatchString(id) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrado",
+			"message": repository.ErrTrashItemNotFound.Error(),
+		})
+		return
+	}
+
+	err := h.trashService.Restore(c.Request.Context(), userID, c.Param("type"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrashType):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "tipo_invalido",
+				"message": err.Error(),
+			})
+		case errors.Is(err, repository.ErrTrashItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "no_encontrado",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "error_servidor",
+				"message": "Error restaurando el elemento",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Elemento restaurado exitosamente"})
+}
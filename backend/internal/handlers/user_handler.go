@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 	"expense-tracker-backend/internal/repository"
@@ -36,6 +37,7 @@ func (h *UserHandler) GetSettings(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.UserSettingsResponse{
 		IncludeSavingsInTotal: user.IncludeSavingsInTotal,
+		AutoCopyBudgets:       user.AutoCopyBudgets,
 	})
 }
 
@@ -53,19 +55,37 @@ func (h *UserHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	if req.IncludeSavingsInTotal == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "datos_invalidos", "message": "include_savings_in_total es requerido"})
+	if req.IncludeSavingsInTotal == nil && req.AutoCopyBudgets == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "datos_invalidos", "message": "include_savings_in_total o auto_copy_budgets es requerido"})
 		return
 	}
 
-	err := h.userRepo.UpdateIncludeSavingsInTotal(c.Request.Context(), userID.(string), *req.IncludeSavingsInTotal)
+	if req.IncludeSavingsInTotal != nil {
+		err := h.userRepo.UpdateIncludeSavingsInTotal(c.Request.Context(), userID.(string), *req.IncludeSavingsInTotal)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error_actualizando", "message": err.Error()})
+			return
+		}
+	}
+
+	// Al activarla, la primera copia automática es la del mes siguiente
+	if req.AutoCopyBudgets != nil {
+		err := h.userRepo.UpdateAutoCopyBudgets(c.Request.Context(), userID.(string), *req.AutoCopyBudgets, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error_actualizando", "message": err.Error()})
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error_actualizando", "message": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "usuario_no_encontrado", "message": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, models.UserSettingsResponse{
-		IncludeSavingsInTotal: *req.IncludeSavingsInTotal,
+		IncludeSavingsInTotal: user.IncludeSavingsInTotal,
+		AutoCopyBudgets:       user.AutoCopyBudgets,
 	})
 }
 
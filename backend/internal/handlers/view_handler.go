@@ -0,0 +1,139 @@
+// Handler de vistas guardadas — endpoints HTTP REST.
+package handlers
+
+import (
+	"net/http"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ViewHandler struct {
+	viewService        *services.ViewService
+	transactionService *services.TransactionService
+}
+
+func NewViewHandler(viewService *services.ViewService, transactionService *services.TransactionService) *ViewHandler {
+	return &ViewHandler{viewService: viewService, transactionService: transactionService}
+}
+
+// GetAll — GET /api/views
+func (h *ViewHandler) GetAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	views, err := h.viewService.GetAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "error_servidor",
+			"message": "Error obteniendo vistas",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"views": views})
+}
+
+// Create — POST /api/views
+func (h *ViewHandler) Create(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	view, err := h.viewService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_creando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// Update — PUT /api/views/:id
+func (h *ViewHandler) Update(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	var req models.UpdateViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "datos_invalidos",
+			"message": "Verifica los datos: " + err.Error(),
+		})
+		return
+	}
+
+	view, err := h.viewService.Update(c.Request.Context(), id, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "error_actualizando",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// Delete — DELETE /api/views/:id
+func (h *ViewHandler) Delete(c *gin.Context) {
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+
+	if err := h.viewService.Delete(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vista eliminada"})
+}
+
+// GetTransactions — GET /api/views/:id/transactions
+// Lista las transacciones de la vista con las fechas relativas calculadas a hoy.
+// Pagina igual que GET /api/transactions (page/limit o cursor).
+func (h *ViewHandler) GetTransactions(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	filter, err := h.viewService.Filter(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": "Vista no encontrada",
+		})
+		return
+	}
+
+	listTransactions(c, h.transactionService, *filter)
+}
+
+// viewScope lee el parámetro view_id de reportes y export. Devuelve el filtro de la vista,
+// o nil si no se envió. Si la vista no existe responde 404 y devuelve ok = false.
+func viewScope(c *gin.Context, viewService *services.ViewService, userID string) (*models.TransactionFilter, bool) {
+	viewID := c.Query("view_id")
+	if viewID == "" {
+		return nil, true
+	}
+	filter, err := viewService.Filter(c.Request.Context(), viewID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "no_encontrada",
+			"message": "Vista no encontrada",
+		})
+		return nil, false
+	}
+	return filter, true
+}
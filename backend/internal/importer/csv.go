@@ -0,0 +1,258 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+)
+
+// MaxRows es el máximo de filas que se aceptan en un archivo.
+const MaxRows = 5000
+
+// ParseCSV lee un extracto CSV según el mapeo de columnas.
+// Los errores de una fila no detienen el proceso: quedan en ImportRow.Errors
+// para que el usuario los vea en la vista previa. Solo se devuelve error si
+// el archivo o el mapeo en sí son inválidos.
+func ParseCSV(data []byte, m models.CSVImportMapping) ([]models.ImportRow, error) {
+	if err := validateMapping(m); err != nil {
+		return nil, err
+	}
+
+	// Quitar el BOM UTF-8 que agrega Excel
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	delimiter := m.Delimiter
+	if delimiter == "" {
+		delimiter = detectDelimiter(data, m.SkipRows)
+	}
+	if delimiter == "\\t" {
+		delimiter = "\t"
+	}
+	if len([]rune(delimiter)) != 1 {
+		return nil, fmt.Errorf("delimitador inválido: %q", delimiter)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = []rune(delimiter)[0]
+	reader.FieldsPerRecord = -1 // Los bancos a veces agregan columnas vacías al final
+	reader.LazyQuotes = true
+
+	line := 0
+	for i := 0; i < m.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, fmt.Errorf("el archivo tiene menos de %d filas", m.SkipRows)
+		}
+		line++
+	}
+
+	hasHeader := m.HasHeader == nil || *m.HasHeader
+	var header []string
+	if hasHeader {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer el encabezado: %w", err)
+		}
+		line++
+		header = record
+	}
+
+	cols, err := resolveColumns(header, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			rows = append(rows, models.ImportRow{Line: line, Errors: []string{"fila CSV inválida: " + err.Error()}})
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("el archivo supera el máximo de %d filas", MaxRows)
+		}
+		rows = append(rows, parseRecord(record, line, cols, m))
+	}
+
+	return rows, nil
+}
+
+// columns guarda la posición de cada columna del mapeo (-1 = no se usa).
+type columns struct {
+	date, amount, debit, credit, description, category int
+}
+
+func validateMapping(m models.CSVImportMapping) error {
+	if m.DateColumn == "" {
+		return errors.New("date_column es obligatorio")
+	}
+	switch m.SignConvention {
+	case "", "negative_expense", "positive_expense":
+		if m.AmountColumn == "" {
+			return errors.New("amount_column es obligatorio")
+		}
+	case "debit_credit":
+		if m.DebitColumn == "" || m.CreditColumn == "" {
+			return errors.New("debit_column y credit_column son obligatorios con sign_convention=debit_credit")
+		}
+	default:
+		return fmt.Errorf("sign_convention desconocida: %s", m.SignConvention)
+	}
+	switch m.AmountFormat {
+	case "", "auto", "co", "us":
+	default:
+		return fmt.Errorf("amount_format desconocido: %s", m.AmountFormat)
+	}
+	if m.SkipRows < 0 {
+		return errors.New("skip_rows no puede ser negativo")
+	}
+	return nil
+}
+
+// resolveColumns busca cada columna del mapeo por nombre de encabezado o por índice.
+func resolveColumns(header []string, m models.CSVImportMapping) (columns, error) {
+	find := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+				return i, nil
+			}
+		}
+		if idx, err := strconv.Atoi(name); err == nil && idx >= 0 {
+			return idx, nil
+		}
+		return -1, fmt.Errorf("no se encontró la columna %q en el encabezado", name)
+	}
+
+	var cols columns
+	var err error
+	if cols.date, err = find(m.DateColumn); err != nil {
+		return cols, err
+	}
+	if cols.amount, err = find(m.AmountColumn); err != nil {
+		return cols, err
+	}
+	if cols.debit, err = find(m.DebitColumn); err != nil {
+		return cols, err
+	}
+	if cols.credit, err = find(m.CreditColumn); err != nil {
+		return cols, err
+	}
+	if cols.description, err = find(m.DescriptionColumn); err != nil {
+		return cols, err
+	}
+	if cols.category, err = find(m.CategoryColumn); err != nil {
+		return cols, err
+	}
+	return cols, nil
+}
+
+// parseRecord interpreta una fila del CSV. Los problemas quedan en row.Errors.
+func parseRecord(record []string, line int, cols columns, m models.CSVImportMapping) models.ImportRow {
+	row := models.ImportRow{Line: line}
+	cell := func(idx int) string {
+		if idx < 0 || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	date, err := ParseDate(cell(cols.date), m.DateFormat)
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	} else {
+		row.Date = date.Format("2006-01-02")
+	}
+
+	var signed float64
+	if m.SignConvention == "debit_credit" {
+		// Débito = sale dinero (gasto), crédito = entra dinero (ingreso)
+		debit, credit := cell(cols.debit), cell(cols.credit)
+		switch {
+		case debit != "" && credit == "":
+			signed, err = ParseAmount(debit, m.AmountFormat)
+			signed = -math.Abs(signed)
+		case credit != "" && debit == "":
+			signed, err = ParseAmount(credit, m.AmountFormat)
+			signed = math.Abs(signed)
+		case debit == "" && credit == "":
+			err = errors.New("la fila no tiene débito ni crédito")
+		default:
+			var d, c float64
+			if d, err = ParseAmount(debit, m.AmountFormat); err == nil {
+				if c, err = ParseAmount(credit, m.AmountFormat); err == nil {
+					signed = math.Abs(c) - math.Abs(d)
+				}
+			}
+		}
+	} else {
+		signed, err = ParseAmount(cell(cols.amount), m.AmountFormat)
+		if m.SignConvention == "positive_expense" {
+			signed = -signed
+		}
+	}
+
+	switch {
+	case err != nil:
+		row.Errors = append(row.Errors, err.Error())
+	case signed == 0:
+		row.Errors = append(row.Errors, "el monto es 0")
+	default:
+		row.Amount = math.Round(math.Abs(signed)*100) / 100
+		row.Type = "income"
+		if signed < 0 {
+			row.Type = "expense"
+		}
+	}
+
+	row.Description = cell(cols.description)
+	if len([]rune(row.Description)) > 255 {
+		row.Description = string([]rune(row.Description)[:255])
+	}
+	row.CategoryRaw = cell(cols.category)
+
+	return row
+}
+
+// detectDelimiter elige entre coma, punto y coma y tabulador según la primera línea útil
+// (después de las filas que se saltan). Los bancos colombianos suelen exportar con ";"
+// porque la coma es el separador decimal.
+func detectDelimiter(data []byte, skipRows int) string {
+	lines := bytes.SplitN(data, []byte("\n"), skipRows+2)
+	firstLine := lines[len(lines)-1]
+	if len(lines) > skipRows {
+		firstLine = lines[skipRows]
+	}
+	best, bestCount := ",", 0
+	for _, d := range []string{",", ";", "\t"} {
+		if n := bytes.Count(firstLine, []byte(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+// Package importer convierte extractos bancarios (CSV u OFX) en filas listas para guardar.
+// Aquí solo se interpreta texto: no hay SQL ni lógica de categorías.
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAmount convierte un monto escrito como texto en float64.
+//
+// Formatos:
+//   - "co":   1.234.567,89 (punto de miles, coma decimal — el de los bancos colombianos)
+//   - "us":   1,234,567.89
+//   - "auto": deduce el formato. Si aparecen punto y coma, el último es el decimal.
+//     Si solo aparece uno y se repite o va seguido de exactamente 3 dígitos, es de miles
+//     (en pesos colombianos casi nunca hay 3 decimales).
+//
+// Acepta símbolos de moneda, espacios, signo +/- y paréntesis contables "(1.500)" como negativo.
+func ParseAmount(raw, format string) (float64, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return 0, fmt.Errorf("monto vacío")
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	// Quitar todo lo que no sea dígito, separador o signo (ej: "$", "COP", espacios)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			b.WriteRune(r)
+		case r == '-':
+			negative = !negative
+		}
+	}
+	s = b.String()
+	if s == "" {
+		return 0, fmt.Errorf("monto inválido: %q", raw)
+	}
+
+	if format == "" || format == "auto" {
+		format = detectAmountFormat(s)
+	}
+
+	switch format {
+	case "co":
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	case "us":
+		s = strings.ReplaceAll(s, ",", "")
+	default:
+		return 0, fmt.Errorf("formato de monto desconocido: %s", format)
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("monto inválido: %q", raw)
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// detectAmountFormat decide si un número usa formato "co" o "us".
+func detectAmountFormat(s string) string {
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			return "co"
+		}
+		return "us"
+	case lastComma >= 0:
+		// Solo comas: "1,234,567" (miles) o "1234,5" (decimal)
+		if strings.Count(s, ",") > 1 || len(s)-lastComma-1 == 3 {
+			return "us"
+		}
+		return "co"
+	case lastDot >= 0:
+		// Solo puntos: "1.234.567" (miles) o "1234.5" (decimal)
+		if strings.Count(s, ".") > 1 || len(s)-lastDot-1 == 3 {
+			return "co"
+		}
+		return "us"
+	default:
+		return "us"
+	}
+}
+
+// ParseDate convierte una fecha según el formato indicado.
+// El formato se escribe con dd, mm, yyyy (o yy): "dd/mm/yyyy", "yyyy-mm-dd", "mm/dd/yyyy".
+// También se acepta un layout de Go ("02/01/2006"). Si la celda trae hora ("15/03/2026 10:22"),
+// se ignora la hora.
+func ParseDate(raw, format string) (time.Time, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("fecha vacía")
+	}
+	if format == "" {
+		format = "dd/mm/yyyy"
+	}
+
+	layout := format
+	if !strings.Contains(layout, "2006") && !strings.Contains(layout, "06") {
+		layout = strings.NewReplacer("yyyy", "2006", "yy", "06", "mm", "01", "dd", "02").Replace(strings.ToLower(format))
+	}
+
+	if t, err := time.Parse(layout, s); err == nil {
+		return t, nil
+	}
+	// Reintentar sin la hora
+	if i := strings.IndexAny(s, " T"); i > 0 {
+		if t, err := time.Parse(layout, s[:i]); err == nil {
+			return t, nil
+		}
+	}
+	// Los bancos a veces omiten los ceros a la izquierda ("5/3/2026")
+	relaxed := strings.NewReplacer("02", "2", "01", "1").Replace(layout)
+	if t, err := time.Parse(relaxed, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("fecha %q no coincide con el formato %s", raw, format)
+}
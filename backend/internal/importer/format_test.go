@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectAmountFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// Punto y coma: el último es el decimal
+		{"1.234.567,89", "co"},
+		{"1,234,567.89", "us"},
+		// Solo comas
+		{"1,234,567", "us"}, // se repite: miles
+		{"1,500", "us"},     // 3 dígitos después: miles
+		{"1234,5", "co"},    // decimal
+		{"45,00", "co"},
+		// Solo puntos
+		{"1.234.567", "co"}, // se repite: miles
+		{"1.500", "co"},     // 3 dígitos después: miles
+		{"1234.5", "us"},    // decimal
+		{"45.00", "us"},
+		// Sin separadores
+		{"45000", "us"},
+	}
+	for _, tt := range tests {
+		if got := detectAmountFormat(tt.in); got != tt.want {
+			t.Errorf("detectAmountFormat(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		raw     string
+		format  string
+		want    float64
+		wantErr bool
+	}{
+		{"1.234.567,89", "auto", 1234567.89, false},
+		{"1,234,567.89", "auto", 1234567.89, false},
+		{"1.500", "auto", 1500, false},
+		{"1.500", "", 1500, false},
+		{"1234,5", "auto", 1234.5, false},
+		{"-45.000", "auto", -45000, false},
+		{"(1.500)", "auto", -1500, false},
+		{"$ -45.000 COP", "auto", -45000, false},
+		{"$1.234.567,89", "auto", 1234567.89, false},
+		{"+2.000,50", "auto", 2000.5, false},
+		{"45000", "auto", 45000, false},
+		// Formato explícito
+		{"1.500", "us", 1.5, false},
+		{"1,500", "co", 1.5, false},
+		{"1.234.567,89", "co", 1234567.89, false},
+		{"1,234,567.89", "us", 1234567.89, false},
+		// Errores
+		{"", "auto", 0, true},
+		{"   ", "auto", 0, true},
+		{"COP", "auto", 0, true},
+		{"1.2.3,4,5", "auto", 0, true},
+		{"1.500", "eu", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.raw, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q, %q) error = %v, wantErr %v", tt.raw, tt.format, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q, %q) = %v, want %v", tt.raw, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		raw     string
+		format  string
+		want    time.Time
+		wantErr bool
+	}{
+		{"15/03/2026", "dd/mm/yyyy", date(2026, 3, 15), false},
+		{"15/03/2026", "", date(2026, 3, 15), false},
+		{"15/03/26", "dd/mm/yy", date(2026, 3, 15), false},
+		{"15/03/2026 10:22", "dd/mm/yyyy", date(2026, 3, 15), false},
+		{"15/03/26 10:22:05", "dd/mm/yy", date(2026, 3, 15), false},
+		{"5/3/2026", "dd/mm/yyyy", date(2026, 3, 5), false},
+		{"2026-03-15", "yyyy-mm-dd", date(2026, 3, 15), false},
+		{"2026-03-15T10:22:00", "yyyy-mm-dd", date(2026, 3, 15), false},
+		{"03/15/2026", "mm/dd/yyyy", date(2026, 3, 15), false},
+		{"15-03-2026", "02-01-2006", date(2026, 3, 15), false},
+		{"", "dd/mm/yyyy", time.Time{}, true},
+		{"32/03/2026", "dd/mm/yyyy", time.Time{}, true},
+		{"03/15/2026", "dd/mm/yyyy", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.raw, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q, %q) error = %v, wantErr %v", tt.raw, tt.format, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q, %q) = %v, want %v", tt.raw, tt.format, got, tt.want)
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
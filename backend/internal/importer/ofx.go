@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/ofx"
+)
+
+// ParseOFX convierte los movimientos de un extracto OFX/QFX en filas de importación.
+// Line es la posición del movimiento en el archivo (empezando en 1).
+// Devuelve también la moneda del extracto (CURDEF), vacía si no viene.
+func ParseOFX(data []byte) ([]models.ImportRow, string, error) {
+	stmt, err := ofx.Parse(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(stmt.Transactions) > MaxRows {
+		return nil, "", fmt.Errorf("el archivo supera el máximo de %d movimientos", MaxRows)
+	}
+
+	rows := make([]models.ImportRow, 0, len(stmt.Transactions))
+	for i, trn := range stmt.Transactions {
+		row := models.ImportRow{
+			Line:        i + 1,
+			Description: describe(trn),
+			ExternalID:  externalID(trn),
+			Errors:      trn.Errors,
+		}
+		if !trn.Posted.IsZero() {
+			row.Date = trn.Posted.Format("2006-01-02")
+		}
+		if len(trn.Errors) == 0 {
+			// TRNAMT se interpreta como un monto de CSV en modo "auto": "-45.000" son
+			// 45.000 pesos, igual que en un extracto CSV del mismo banco
+			amount, err := ParseAmount(trn.Amount, "auto")
+			switch {
+			case err != nil:
+				row.Errors = append(row.Errors, "TRNAMT: "+err.Error())
+			case amount == 0:
+				row.Errors = append(row.Errors, "el monto es 0")
+			default:
+				row.Amount = math.Round(math.Abs(amount)*100) / 100
+				row.Type = "income"
+				if amount < 0 {
+					row.Type = "expense"
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, stmt.Currency, nil
+}
+
+// describe arma la descripción con NAME y MEMO (si aporta algo distinto).
+func describe(trn ofx.Transaction) string {
+	desc := trn.Name
+	switch {
+	case desc == "":
+		desc = trn.Memo
+	case trn.Memo != "" && !strings.EqualFold(trn.Memo, trn.Name):
+		desc += " - " + trn.Memo
+	}
+	if len([]rune(desc)) > 255 {
+		desc = string([]rune(desc)[:255])
+	}
+	return desc
+}
+
+// externalID combina cuenta y FITID: el FITID solo es único dentro de una cuenta.
+func externalID(trn ofx.Transaction) string {
+	if trn.FITID == "" {
+		return ""
+	}
+	if trn.AccountID == "" {
+		return "ofx:" + trn.FITID
+	}
+	return "ofx:" + trn.AccountID + ":" + trn.FITID
+}
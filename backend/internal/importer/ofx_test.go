@@ -0,0 +1,43 @@
+package importer
+
+import "testing"
+
+func TestParseOFXAmounts(t *testing.T) {
+	data := []byte("OFXHEADER:100\n<OFX><CURDEF>COP\n" +
+		"<STMTTRN><DTPOSTED>20260315<TRNAMT>-45.000<FITID>1<NAME>Cafe</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20260316<TRNAMT>1.500.000,50<FITID>2<NAME>Nomina</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20260317<TRNAMT>-12.34<FITID>3<NAME>Coffee</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20260318<TRNAMT>0.00<FITID>4<NAME>Cero</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20260319<TRNAMT>abc<FITID>5<NAME>Roto</STMTTRN>\n" +
+		"</OFX>")
+
+	rows, currency, err := ParseOFX(data)
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+	if currency != "COP" {
+		t.Errorf("currency = %q, want COP", currency)
+	}
+
+	tests := []struct {
+		amount    float64
+		txType    string
+		hasErrors bool
+	}{
+		{45000, "expense", false},
+		{1500000.50, "income", false},
+		{12.34, "expense", false},
+		{0, "", true},
+		{0, "", true},
+	}
+	if len(rows) != len(tests) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(tests))
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		if row.Amount != tt.amount || row.Type != tt.txType || (len(row.Errors) > 0) != tt.hasErrors {
+			t.Errorf("row %d = amount %v type %q errors %v, want amount %v type %q errors %v",
+				i+1, row.Amount, row.Type, row.Errors, tt.amount, tt.txType, tt.hasErrors)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package models
 
 import "time"
 
-// Budget representa un presupuesto mensual para una categoría.
-// Ejemplo: "En febrero 2026, no quiero gastar más de $500.000 COP en comida"
+// Budget representa un presupuesto de una categoría para un periodo.
+// Ejemplo: "En febrero 2026, no quiero gastar más de $500.000 COP en comida",
+// "Este año, $1.500.000 para el SOAT y el impuesto vehicular"
 type Budget struct {
 	ID            string    `json:"id"`
 	UserID        string    `json:"user_id"`
@@ -12,17 +13,88 @@ type Budget struct {
 	CategoryColor string    `json:"category_color,omitempty"` // Se llena con JOIN
 	CategoryIcon  string    `json:"category_icon,omitempty"`  // Se llena con JOIN
 	AmountLimit   float64   `json:"amount_limit"`
-	Spent         float64   `json:"spent"`    // Cuánto se ha gastado (calculado con SUM)
-	Month         int       `json:"month"`
-	Year          int       `json:"year"`
+	Spent         float64   `json:"spent"`       // Cuánto se ha gastado (calculado con SUM)
+	Rollover      string    `json:"rollover"`    // Qué pasa al periodo siguiente (ver BudgetRollover*)
+	Carried       float64   `json:"carried"`     // Arrastrado de los periodos anteriores (negativo = se pasó)
+	Available     float64   `json:"available"`   // AmountLimit + Carried
+	PeriodType    string    `json:"period_type"` // Ver BudgetPeriod*
+	StartDate     time.Time `json:"-"`
+	StartDateStr  string    `json:"start_date"`
+	EndDate       time.Time `json:"-"`
+	EndDateStr    string    `json:"end_date"` // Incluido: el periodo va de start_date a end_date
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// FormatDates llena los campos string de fecha a partir de los time.Time.
+func (b *Budget) FormatDates() {
+	b.StartDateStr = b.StartDate.Format("2006-01-02")
+	b.EndDateStr = b.EndDate.Format("2006-01-02")
+}
+
+// Tipos de periodo de un presupuesto. Salvo custom, los periodos son de calendario:
+// la semana empieza el lunes y el trimestre en enero, abril, julio u octubre.
+const (
+	BudgetPeriodWeekly    = "weekly"
+	BudgetPeriodMonthly   = "monthly"
+	BudgetPeriodQuarterly = "quarterly"
+	BudgetPeriodYearly    = "yearly"
+	BudgetPeriodCustom    = "custom" // Rango libre entre start_date y end_date
+)
+
+// Modos de arrastre de un presupuesto al periodo siguiente: el presupuesto de la misma
+// categoría y el mismo tipo de periodo que empieza justo al día siguiente de end_date.
+const (
+	BudgetRolloverNone              = "none"                // Cada periodo empieza de cero
+	BudgetRolloverSurplus           = "surplus"             // Lo que sobra pasa al periodo siguiente
+	BudgetRolloverSurplusAndDeficit = "surplus_and_deficit" // También lo que se pasó (se resta)
+)
+
 // CreateBudgetRequest es lo que el frontend envía para crear/actualizar un presupuesto.
+// Salvo en custom, start_date puede ser cualquier día del periodo: se ajusta a su inicio.
+// Sin start_date se usan month y year (presupuesto mensual, como antes de los periodos).
 type CreateBudgetRequest struct {
 	CategoryID  string  `json:"category_id" binding:"required,uuid"`
 	AmountLimit float64 `json:"amount_limit" binding:"required,gt=0"`
-	Month       int     `json:"month" binding:"required,min=1,max=12"`
-	Year        int     `json:"year" binding:"required,min=2020,max=2100"`
+	PeriodType  string  `json:"period_type" binding:"omitempty,oneof=weekly monthly quarterly yearly custom"` // Vacío = monthly
+	StartDate   string  `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate     string  `json:"end_date" binding:"omitempty,datetime=2006-01-02"` // Solo para custom
+	Month       int     `json:"month" binding:"omitempty,min=1,max=12"`
+	Year        int     `json:"year" binding:"omitempty,min=2020,max=2100"`
+	// Vacío = "none" al crear, sin cambios al actualizar
+	Rollover string `json:"rollover" binding:"omitempty,oneof=none surplus surplus_and_deficit"`
+}
+
+// CopyBudgetsRequest es el body de POST /api/budgets/copy: copia los presupuestos de tipo
+// PeriodType de un periodo a otro, con los límites ajustados en AdjustPercent (ej: 5 = +5%, -10 = -10%).
+// Cada periodo se indica con una fecha cualquiera dentro de él (from_start_date, to_start_date)
+// o, para presupuestos mensuales, con mes y año (como antes de los periodos).
+type CopyBudgetsRequest struct {
+	PeriodType    string  `json:"period_type" binding:"omitempty,oneof=weekly monthly quarterly yearly"` // Vacío = monthly
+	FromStartDate string  `json:"from_start_date" binding:"omitempty,datetime=2006-01-02"`
+	ToStartDate   string  `json:"to_start_date" binding:"omitempty,datetime=2006-01-02"`
+	FromMonth     int     `json:"from_month" binding:"omitempty,min=1,max=12"`
+	FromYear      int     `json:"from_year" binding:"omitempty,min=2020,max=2100"`
+	ToMonth       int     `json:"to_month" binding:"omitempty,min=1,max=12"`
+	ToYear        int     `json:"to_year" binding:"omitempty,min=2020,max=2100"`
+	AdjustPercent float64 `json:"adjust_percent" binding:"gt=-100,lte=1000"`
+	Overwrite     bool    `json:"overwrite"` // Si true, reemplaza los que ya existan en el periodo destino
+}
+
+// BudgetPlan es un presupuesto por crear en un periodo concreto, al copiar presupuestos
+// o aplicar una plantilla.
+type BudgetPlan struct {
+	BudgetTemplateItem
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// BudgetCopyResult es la respuesta al copiar presupuestos o aplicar una plantilla.
+// Skipped son los que no se crearon porque ya existían en su periodo (sin overwrite) o
+// porque su categoría ya no existe o está archivada. Budgets son los activos en el
+// periodo destino (de cualquier tipo de periodo).
+type BudgetCopyResult struct {
+	Created int      `json:"created"`
+	Skipped int      `json:"skipped"`
+	Budgets []Budget `json:"budgets"`
 }
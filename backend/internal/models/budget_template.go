@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// BudgetTemplate es un conjunto de presupuestos con nombre que se puede aplicar a
+// cualquier periodo. Ejemplo: "Mes normal", "Diciembre".
+type BudgetTemplate struct {
+	ID        string               `json:"id"`
+	UserID    string               `json:"user_id"`
+	Name      string               `json:"name"`
+	Items     []BudgetTemplateItem `json:"items"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// BudgetTemplateItem es un presupuesto de una plantilla (o de un periodo que se copia).
+// Un ítem no puede ser custom: al aplicarlo, su periodo es el de su tipo que contiene la fecha.
+type BudgetTemplateItem struct {
+	CategoryID  string  `json:"category_id" binding:"required,uuid"`
+	AmountLimit float64 `json:"amount_limit" binding:"required,gt=0"`
+	PeriodType  string  `json:"period_type" binding:"omitempty,oneof=weekly monthly quarterly yearly"` // Vacío = monthly
+	Rollover    string  `json:"rollover,omitempty" binding:"omitempty,oneof=none surplus surplus_and_deficit"`
+}
+
+// CreateBudgetTemplateRequest es lo que el frontend envía para guardar una plantilla.
+type CreateBudgetTemplateRequest struct {
+	Name  string               `json:"name" binding:"required,min=1,max=100"`
+	Items []BudgetTemplateItem `json:"items" binding:"required,min=1,max=200,dive"`
+}
+
+// UpdateBudgetTemplateRequest permite renombrar una plantilla o reemplazar sus ítems.
+type UpdateBudgetTemplateRequest struct {
+	Name  string               `json:"name" binding:"omitempty,min=1,max=100"`
+	Items []BudgetTemplateItem `json:"items" binding:"omitempty,min=1,max=200,dive"`
+}
+
+// ApplyBudgetTemplateRequest es el body de POST /api/budgets/templates/:id/apply.
+// Cada ítem se crea en el periodo de su tipo que contiene start_date (o el día 1 de
+// month y year). Con period_type solo se aplican los ítems de ese tipo.
+type ApplyBudgetTemplateRequest struct {
+	PeriodType    string  `json:"period_type" binding:"omitempty,oneof=weekly monthly quarterly yearly"`
+	StartDate     string  `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	Month         int     `json:"month" binding:"omitempty,min=1,max=12"`
+	Year          int     `json:"year" binding:"omitempty,min=2020,max=2100"`
+	AdjustPercent float64 `json:"adjust_percent" binding:"gt=-100,lte=1000"` // Ej: 5 = +5%
+	Overwrite     bool    `json:"overwrite"`                                 // Si true, reemplaza los que ya existan en el periodo
+}
@@ -0,0 +1,41 @@
+package models
+
+// Acciones de POST /api/transactions/bulk.
+const (
+	BulkDelete      = "delete"
+	BulkSetCategory = "set_category"
+	BulkAddTag      = "add_tag"
+	BulkSetDate     = "set_date"
+	BulkSetCurrency = "set_currency"
+)
+
+// BulkTransactionRequest aplica una misma acción a varias transacciones.
+// Las transacciones se eligen con IDs o con Filter (los mismos filtros de una vista
+// guardada), nunca con los dos. Cada acción usa solo su campo: CategoryID, Tag, Date o Currency.
+type BulkTransactionRequest struct {
+	Action string       `json:"action" binding:"required,oneof=delete set_category add_tag set_date set_currency"`
+	IDs    []string     `json:"ids" binding:"omitempty,max=1000,dive,uuid"`
+	Filter *ViewFilters `json:"filter"`
+
+	CategoryID string `json:"category_id" binding:"omitempty,uuid"`
+	Tag        string `json:"tag" binding:"omitempty,min=1,max=50"`
+	Date       string `json:"date" binding:"omitempty,datetime=2006-01-02"`
+	Currency   string `json:"currency" binding:"omitempty,len=3"`
+}
+
+// BulkItemResult es el resultado de la acción sobre una transacción.
+type BulkItemResult struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// BulkResult resume una operación masiva. Las transacciones que fallan no impiden
+// que se apliquen las demás; cada una trae su resultado en Results.
+type BulkResult struct {
+	Action    string           `json:"action"`
+	Matched   int              `json:"matched"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
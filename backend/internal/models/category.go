@@ -12,7 +12,12 @@ type Category struct {
 	Color     string    `json:"color"`     // Color hex: "#6366f1"
 	Icon      string    `json:"icon"`      // Nombre del ícono: "utensils", "car", etc.
 	Type      string    `json:"type"`      // "income" o "expense"
+	ParentID  *string   `json:"parent_id"` // Categoría padre (nil = categoría principal)
+	Archived  bool      `json:"archived"`  // Archivada: no se ofrece para registrar, pero sigue en los reportes
 	CreatedAt time.Time `json:"created_at"`
+
+	// Solo en GET /api/categories?tree=true: subcategorías directas
+	Children []Category `json:"children,omitempty"`
 }
 
 // CreateCategoryRequest es lo que el frontend envía para crear una categoría.
@@ -22,6 +27,12 @@ type CreateCategoryRequest struct {
 	Color    string `json:"color" binding:"required,len=7"`
 	Icon     string `json:"icon" binding:"required,min=1"`
 	Type     string `json:"type" binding:"required,oneof=income expense"`
+	ParentID string `json:"parent_id" binding:"omitempty,uuid"` // Opcional: crearla como subcategoría
+}
+
+// MergeCategoryRequest indica en qué categoría se fusiona otra (POST /api/categories/:id/merge).
+type MergeCategoryRequest struct {
+	Into string `json:"into" binding:"required,uuid"`
 }
 
 // UpdateCategoryRequest permite actualizar campos de una categoría.
@@ -30,4 +41,7 @@ type UpdateCategoryRequest struct {
 	Nickname string `json:"nickname"`
 	Color    string `json:"color" binding:"omitempty,len=7"`
 	Icon     string `json:"icon" binding:"omitempty,min=1"`
+	Archived *bool  `json:"archived"` // nil = sin cambios
+	// nil = sin cambios, "" = pasa a ser categoría principal
+	ParentID *string `json:"parent_id" binding:"omitempty,uuid|len=0"`
 }
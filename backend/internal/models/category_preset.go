@@ -0,0 +1,39 @@
+package models
+
+// CategoryPreset es un paquete de categorías predeterminadas, con los textos en el
+// idioma pedido (GET /api/categories/presets?lang=).
+type CategoryPreset struct {
+	ID             string           `json:"id"`
+	Version        int              `json:"version"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description"`
+	Categories     []PresetCategory `json:"categories"`
+	AppliedVersion *int             `json:"applied_version"` // nil = el usuario no lo ha aplicado
+}
+
+// PresetCategory es una categoría de un paquete.
+type PresetCategory struct {
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Icon      string `json:"icon"`
+	Type      string `json:"type"`
+	ParentKey string `json:"parent_key,omitempty"`
+
+	// Nombres en todos los idiomas: una categoría del usuario con cualquiera de ellos
+	// (y el mismo tipo) cuenta como que ya la tiene
+	Names []string `json:"-"`
+}
+
+// ApplyPresetRequest es el body (opcional) de POST /api/categories/presets/:id/apply.
+type ApplyPresetRequest struct {
+	Language string `json:"language" binding:"omitempty,oneof=es en"`
+}
+
+// ApplyPresetResponse dice qué categorías creó un paquete. Existing son las del paquete
+// que el usuario ya tenía y se dejaron como estaban.
+type ApplyPresetResponse struct {
+	Preset   CategoryPreset `json:"preset"`
+	Created  []Category     `json:"created"`
+	Existing int            `json:"existing"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+)
+
+// TransactionCursor marca la posición de la última transacción de una página en el
+// orden (date, created_at, id). La siguiente página empieza justo después de ella.
+type TransactionCursor struct {
+	Date      string    `json:"d"` // "2006-01-02"
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// ErrInvalidCursor se devuelve cuando el cursor recibido no se puede leer.
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// cursorIDPattern valida el ID del cursor antes de compararlo con la columna uuid t.id.
+var cursorIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// CursorOf devuelve el cursor que apunta a una transacción.
+func CursorOf(t *Transaction) TransactionCursor {
+	return TransactionCursor{Date: t.DateStr, CreatedAt: t.CreatedAt, ID: t.ID}
+}
+
+// Encode convierte el cursor en un texto opaco apto para la URL.
+func (c TransactionCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTransactionCursor lee un cursor generado por Encode.
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c TransactionCursor
+	if err := json.Unmarshal(data, &c); err != nil || !cursorIDPattern.MatchString(c.ID) || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := time.Parse("2006-01-02", c.Date); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// CSVImportMapping le dice al importador cómo leer el CSV del banco.
+// Para OFX solo se usan Currency y las categorías por defecto.
+// La categoría de cada fila se busca en este orden: columna de categoría, reglas de
+// categorización y, por último, la categoría por defecto de su tipo.
+// Las columnas se identifican por el nombre del encabezado (sin importar mayúsculas)
+// o por su posición empezando en 0 ("0", "1", ...) si el archivo no tiene encabezado.
+type CSVImportMapping struct {
+	Delimiter  string `json:"delimiter"`  // ",", ";" o "\t". Vacío = detectar automáticamente
+	HasHeader  *bool  `json:"has_header"` // Por defecto true
+	SkipRows   int    `json:"skip_rows"`  // Filas a ignorar antes del encabezado (títulos del banco, etc.)
+	DateColumn string `json:"date_column"`
+	DateFormat string `json:"date_format"` // "dd/mm/yyyy" (por defecto), "yyyy-mm-dd", "mm/dd/yyyy"...
+
+	// Monto: una sola columna con signo, o columnas separadas de débito y crédito
+	AmountColumn   string `json:"amount_column"`
+	DebitColumn    string `json:"debit_column"`
+	CreditColumn   string `json:"credit_column"`
+	AmountFormat   string `json:"amount_format"`   // "auto" (por defecto), "co" (1.234.567,89) o "us" (1,234,567.89)
+	SignConvention string `json:"sign_convention"` // "negative_expense" (por defecto), "positive_expense" o "debit_credit"
+
+	DescriptionColumn string `json:"description_column"`
+	CategoryColumn    string `json:"category_column"` // Opcional: se busca por nombre o alias de la categoría
+
+	// Categorías a usar cuando la fila no trae una (o no se encuentra)
+	DefaultIncomeCategoryID  string `json:"default_income_category_id"`
+	DefaultExpenseCategoryID string `json:"default_expense_category_id"`
+
+	Currency string `json:"currency"` // Por defecto "COP"
+}
+
+// ImportRow es una fila del archivo ya interpretada, lista para revisar o guardar.
+type ImportRow struct {
+	Line         int     `json:"line"` // Número de línea en el archivo (empezando en 1)
+	Date         string  `json:"date"` // "2006-01-02"
+	Amount       float64 `json:"amount"`
+	Type         string  `json:"type"` // "income" o "expense"
+	Description  string  `json:"description"`
+	CategoryRaw  string  `json:"category_raw,omitempty"` // Texto de la columna de categoría tal cual venía
+	CategoryID   string  `json:"category_id,omitempty"`
+	CategoryName string  `json:"category_name,omitempty"`
+	RuleID       string  `json:"rule_id,omitempty"`     // Regla que asignó la categoría (si aplica)
+	ExternalID   string  `json:"external_id,omitempty"` // FITID del OFX (con la cuenta)
+	Duplicate    bool    `json:"duplicate,omitempty"`   // Ya se importó antes: se omite al guardar
+
+	// Transacciones existentes que parecen la misma (mismo monto, fecha cercana, descripción parecida).
+	// Se guardan igual salvo que se pida skip_duplicates.
+	PossibleDuplicates []Transaction `json:"possible_duplicates,omitempty"`
+	Errors             []string      `json:"errors,omitempty"`
+}
+
+// ImportBatch es un import confirmado. Permite deshacer de una vez todo lo que creó.
+type ImportBatch struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	Source           string    `json:"source"` // "csv" u "ofx"
+	Filename         string    `json:"filename"`
+	RowCount         int       `json:"row_count"`         // Transacciones creadas al importar
+	TransactionCount int       `json:"transaction_count"` // Transacciones del lote que siguen existiendo
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ImportPreview es la respuesta del import: qué se va a guardar (dry run) o qué se guardó.
+type ImportPreview struct {
+	DryRun                bool        `json:"dry_run"`
+	TotalRows             int         `json:"total_rows"`
+	ValidRows             int         `json:"valid_rows"`
+	ErrorRows             int         `json:"error_rows"`
+	DuplicateRows         int         `json:"duplicate_rows"` // Ya existían (mismo ID externo): no se vuelven a guardar
+	PossibleDuplicateRows int         `json:"possible_duplicate_rows"`
+	Imported              int         `json:"imported"`
+	BatchID               string      `json:"batch_id,omitempty"` // Lote creado al confirmar (vacío en dry run)
+	Rows                  []ImportRow `json:"rows"`
+}
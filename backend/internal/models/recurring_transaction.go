@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// RecurringTransaction es una plantilla de transacción que se repite en el tiempo.
+// Ejemplo: "Arriendo, $1.200.000, gasto, el día 5 de cada mes".
+// El worker de recurrentes la convierte en filas de transactions cuando llega la fecha.
+type RecurringTransaction struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	CategoryID    string     `json:"category_id"`
+	CategoryName  string     `json:"category_name,omitempty"`  // Se llena con JOIN
+	CategoryColor string     `json:"category_color,omitempty"` // Se llena con JOIN
+	CategoryIcon  string     `json:"category_icon,omitempty"`  // Se llena con JOIN
+	Amount        float64    `json:"amount"`
+	Type          string     `json:"type"`
+	Description   string     `json:"description"`
+	Currency      string     `json:"currency"`
+	Frequency     string     `json:"frequency"`              // "daily", "weekly", "monthly" o "yearly"
+	DayOfMonth    *int       `json:"day_of_month,omitempty"` // Solo para frecuencia mensual
+	StartDate     time.Time  `json:"-"`
+	StartDateStr  string     `json:"start_date"`
+	EndDate       *time.Time `json:"-"`
+	EndDateStr    string     `json:"end_date,omitempty"`
+	NextRunDate   time.Time  `json:"-"`
+	NextRunStr    string     `json:"next_run_date"`
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// FormatDates llena los campos string de fecha a partir de los time.Time.
+func (r *RecurringTransaction) FormatDates() {
+	r.StartDateStr = r.StartDate.Format("2006-01-02")
+	r.NextRunStr = r.NextRunDate.Format("2006-01-02")
+	if r.EndDate != nil {
+		r.EndDateStr = r.EndDate.Format("2006-01-02")
+	}
+}
+
+// CreateRecurringRequest es lo que el frontend envía para crear una plantilla recurrente.
+type CreateRecurringRequest struct {
+	CategoryID  string  `json:"category_id" binding:"required,uuid"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Type        string  `json:"type" binding:"required,oneof=income expense"`
+	Description string  `json:"description" binding:"max=255"`
+	Currency    string  `json:"currency" binding:"omitempty,len=3"`
+	Frequency   string  `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	DayOfMonth  int     `json:"day_of_month" binding:"omitempty,min=1,max=31"`
+	StartDate   string  `json:"start_date" binding:"required"` // "2006-01-02"
+	EndDate     string  `json:"end_date"`                      // Opcional
+}
+
+// UpdateRecurringRequest permite actualizar una plantilla recurrente.
+// Si PropagateFrom viene con una fecha, los cambios de monto, categoría, descripción
+// y moneda también se aplican a las transacciones ya generadas desde esa fecha.
+type UpdateRecurringRequest struct {
+	CategoryID    string  `json:"category_id" binding:"omitempty,uuid"`
+	Amount        float64 `json:"amount" binding:"omitempty,gt=0"`
+	Description   string  `json:"description" binding:"omitempty,max=255"`
+	Currency      string  `json:"currency" binding:"omitempty,len=3"`
+	EndDate       *string `json:"end_date"` // "" para quitar la fecha de fin
+	Active        *bool   `json:"active"`
+	PropagateFrom string  `json:"propagate_from"` // "2006-01-02" (opcional)
+}
@@ -8,13 +8,20 @@ type MonthlySummary struct {
 	TotalExpense float64           `json:"total_expense"`
 	Balance      float64           `json:"balance"` // income - expense
 	ByCategory   []CategorySummary `json:"by_category"`
+
+	// Transferencias del mes (no cuentan como ingreso ni gasto)
+	TransfersToSavings   float64 `json:"transfers_to_savings"`   // flujo principal → ahorros
+	TransfersFromSavings float64 `json:"transfers_from_savings"` // ahorros → flujo principal
 }
 
 // CategorySummary muestra el total gastado/ganado en una categoría específica.
+// En el resumen agrupado (rollup) el total de una categoría principal incluye el de
+// todas sus subcategorías.
 type CategorySummary struct {
 	CategoryID    string  `json:"category_id"`
 	CategoryName  string  `json:"category_name"`
 	CategoryColor string  `json:"category_color"`
+	ParentID      *string `json:"parent_id,omitempty"`
 	Type          string  `json:"type"`
 	Total         float64 `json:"total"`
 }
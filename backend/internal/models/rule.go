@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// CategorizationRule asigna automáticamente una categoría a las transacciones que coinciden.
+// Ejemplo: "descripción contiene 'uber', gasto, entre $5.000 y $80.000 → Transporte".
+// Las reglas se evalúan por Priority (menor número primero); gana la primera que coincide.
+type CategorizationRule struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	CategoryID    string    `json:"category_id"`
+	CategoryName  string    `json:"category_name,omitempty"`  // Se llena con JOIN
+	CategoryColor string    `json:"category_color,omitempty"` // Se llena con JOIN
+	Name          string    `json:"name"`
+	MatchType     string    `json:"match_type"` // "contains" o "regex"
+	Pattern       string    `json:"pattern"`
+	MinAmount     *float64  `json:"min_amount,omitempty"`
+	MaxAmount     *float64  `json:"max_amount,omitempty"`
+	Type          string    `json:"type"` // "income" o "expense" (el de la categoría)
+	Priority      int       `json:"priority"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateRuleRequest es lo que el frontend envía para crear una regla.
+// Type es opcional: si viene, debe coincidir con el tipo de la categoría.
+type CreateRuleRequest struct {
+	Name       string   `json:"name" binding:"required,min=1,max=100"`
+	CategoryID string   `json:"category_id" binding:"required,uuid"`
+	MatchType  string   `json:"match_type" binding:"omitempty,oneof=contains regex"` // Por defecto "contains"
+	Pattern    string   `json:"pattern" binding:"required,min=1,max=255"`
+	MinAmount  *float64 `json:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount  *float64 `json:"max_amount" binding:"omitempty,gt=0"`
+	Type       string   `json:"type" binding:"omitempty,oneof=income expense"`
+	Priority   *int     `json:"priority"` // Por defecto 100
+	Active     *bool    `json:"active"`   // Por defecto true
+}
+
+// UpdateRuleRequest permite actualizar una regla. Los campos nil no se tocan.
+// Para quitar un límite de monto se envía 0.
+type UpdateRuleRequest struct {
+	Name       string   `json:"name" binding:"omitempty,min=1,max=100"`
+	CategoryID string   `json:"category_id" binding:"omitempty,uuid"`
+	MatchType  string   `json:"match_type" binding:"omitempty,oneof=contains regex"`
+	Pattern    string   `json:"pattern" binding:"omitempty,min=1,max=255"`
+	MinAmount  *float64 `json:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount  *float64 `json:"max_amount" binding:"omitempty,gte=0"`
+	Priority   *int     `json:"priority"`
+	Active     *bool    `json:"active"`
+}
+
+// ApplyRulesRequest recategoriza transacciones existentes con las reglas.
+// Sin RuleIDs se usan todas las reglas activas. Por defecto es un dry run.
+type ApplyRulesRequest struct {
+	RuleIDs  []string `json:"rule_ids" binding:"omitempty,dive,uuid"`
+	DateFrom string   `json:"date_from"` // "2006-01-02" (opcional)
+	DateTo   string   `json:"date_to"`   // "2006-01-02" (opcional)
+	DryRun   *bool    `json:"dry_run"`   // Por defecto true
+}
+
+// RuleChange es una transacción cuya categoría cambia (o cambiaría) al aplicar las reglas.
+type RuleChange struct {
+	TransactionID    string  `json:"transaction_id"`
+	Date             string  `json:"date"`
+	Description      string  `json:"description"`
+	Amount           float64 `json:"amount"`
+	FromCategoryID   string  `json:"from_category_id"`
+	FromCategoryName string  `json:"from_category_name"`
+	ToCategoryID     string  `json:"to_category_id"`
+	ToCategoryName   string  `json:"to_category_name"`
+	RuleID           string  `json:"rule_id"`
+	RuleName         string  `json:"rule_name"`
+}
+
+// ApplyRulesResponse resume el resultado de aplicar reglas.
+type ApplyRulesResponse struct {
+	DryRun  bool         `json:"dry_run"`
+	Checked int          `json:"checked"` // Transacciones revisadas
+	Updated int          `json:"updated"` // Transacciones recategorizadas (0 en dry run)
+	Changes []RuleChange `json:"changes"`
+}
@@ -16,28 +16,118 @@ type SavingsAccount struct {
 	Notes     string    `json:"notes"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Meta de ahorro (opcional)
+	TargetAmount  *float64     `json:"target_amount,omitempty"`
+	TargetDate    *time.Time   `json:"-"`
+	TargetDateStr string       `json:"target_date,omitempty"` // "2006-01-02"
+	Goal          *SavingsGoal `json:"goal,omitempty"`        // Progreso calculado (solo si hay target_amount)
+}
+
+// SavingsGoal es el progreso calculado de la meta de una cuenta de ahorro.
+// El aporte mensual promedio sale del historial de movimientos de los últimos meses.
+type SavingsGoal struct {
+	TargetAmount           float64  `json:"target_amount"`
+	TargetDate             string   `json:"target_date,omitempty"`
+	Balance                float64  `json:"balance"`
+	Remaining              float64  `json:"remaining"`
+	ProgressPercent        float64  `json:"progress_percent"`               // 0 a 100
+	AvgMonthlyContribution float64  `json:"avg_monthly_contribution"`       // Aporte neto promedio por mes
+	RequiredMonthly        *float64 `json:"required_monthly_contribution"`  // Solo si hay target_date
+	ProjectedCompletion    string   `json:"projected_completion,omitempty"` // Fecha estimada al ritmo actual
+	OnTrack                *bool    `json:"on_track,omitempty"`             // Solo si hay target_date
+	Completed              bool     `json:"completed"`
 }
 
 // CreateSavingsAccountRequest — datos para crear una cuenta de ahorro.
 type CreateSavingsAccountRequest struct {
-	Name    string  `json:"name" binding:"required,min=1"`
-	Balance float64 `json:"balance"`
-	Color   string  `json:"color" binding:"required"`
-	Icon    string  `json:"icon" binding:"required"`
-	Notes   string  `json:"notes"`
+	Name         string   `json:"name" binding:"required,min=1"`
+	Balance      float64  `json:"balance"`
+	Color        string   `json:"color" binding:"required"`
+	Icon         string   `json:"icon" binding:"required"`
+	Notes        string   `json:"notes"`
+	TargetAmount *float64 `json:"target_amount" binding:"omitempty,gt=0"`
+	TargetDate   string   `json:"target_date"` // "2006-01-02" (opcional)
 }
 
 // UpdateSavingsAccountRequest — datos para actualizar una cuenta de ahorro.
+// Balance y Notes son punteros: nil = no cambiar (así 0 y "" son valores válidos).
+// TargetAmount y TargetDate también: nil = no cambiar, 0 / "" = quitar la meta.
 type UpdateSavingsAccountRequest struct {
-	Name    string  `json:"name"`
-	Balance float64 `json:"balance"`
-	Color   string  `json:"color"`
-	Icon    string  `json:"icon"`
-	Notes   string  `json:"notes"`
+	Name         string   `json:"name"`
+	Balance      *float64 `json:"balance"`
+	Color        string   `json:"color"`
+	Icon         string   `json:"icon"`
+	Notes        *string  `json:"notes"`
+	TargetAmount *float64 `json:"target_amount"`
+	TargetDate   *string  `json:"target_date"`
 }
 
 // AdjustBalanceRequest — para agregar o quitar dinero de una cuenta.
 type AdjustBalanceRequest struct {
 	Amount float64 `json:"amount" binding:"required"`
 	Type   string  `json:"type" binding:"required,oneof=deposit withdraw"`
+	Note   string  `json:"note" binding:"max=255"` // Queda en el historial de movimientos
+}
+
+// FormatTargetDate llena TargetDateStr a partir de TargetDate.
+func (a *SavingsAccount) FormatTargetDate() {
+	if a.TargetDate != nil {
+		a.TargetDateStr = a.TargetDate.Format("2006-01-02")
+	}
+}
+
+// SavingsContribution resume los aportes de una cuenta para estimar su meta.
+type SavingsContribution struct {
+	Net           float64   // Suma neta de movimientos (sin el saldo inicial) desde el inicio de la ventana
+	FirstMovement time.Time // Fecha del primer movimiento de la cuenta
+}
+
+// SavingsMovement es una fila inmutable del libro de movimientos de una cuenta.
+// Amount tiene signo: positivo si entra dinero, negativo si sale.
+// Date es el día al que corresponde (la fecha de la transferencia, si viene de una);
+// BalanceAfter es el saldo de la cuenta después del movimiento, en orden de Date.
+type SavingsMovement struct {
+	ID            string    `json:"id"`
+	AccountID     string    `json:"account_id"`
+	Type          string    `json:"type"` // "initial", "deposit", "withdraw", "adjustment", "transfer_in", "transfer_out", "transfer_reversal"
+	Amount        float64   `json:"amount"`
+	BalanceAfter  float64   `json:"balance_after"`
+	Note          string    `json:"note"`
+	TransactionID *string   `json:"transaction_id,omitempty"` // Transferencia que lo originó (si aplica)
+	Date          time.Time `json:"-"`
+	DateStr       string    `json:"date"` // "2006-01-02"
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SavingsMovementFilter contiene los filtros para listar movimientos de una cuenta.
+type SavingsMovementFilter struct {
+	AccountID string
+	UserID    string
+	DateFrom  string // "2006-01-02"
+	DateTo    string // "2006-01-02"
+	Page      int
+	Limit     int
+}
+
+// SavingsMovementListResponse incluye los movimientos y metadata de paginación.
+type SavingsMovementListResponse struct {
+	Movements  []SavingsMovement `json:"movements"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// BalancePoint es el saldo de una cuenta al cierre de un periodo (día, semana o mes).
+type BalancePoint struct {
+	Period  string  `json:"period"` // Inicio del periodo: "2026-02-01"
+	Balance float64 `json:"balance"`
+}
+
+// SavingsHistoryResponse es la serie de saldos para graficar el crecimiento de una cuenta.
+type SavingsHistoryResponse struct {
+	AccountID   string         `json:"account_id"`
+	Granularity string         `json:"granularity"`
+	Points      []BalancePoint `json:"points"`
 }
@@ -0,0 +1,11 @@
+package models
+
+// CategorySuggestion es una categoría sugerida por el clasificador para una transacción.
+type CategorySuggestion struct {
+	CategoryID    string  `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	CategoryColor string  `json:"category_color"`
+	CategoryIcon  string  `json:"category_icon"`
+	Type          string  `json:"type"`
+	Confidence    float64 `json:"confidence"` // 0 a 1; las sugerencias de una respuesta suman ≤ 1
+}
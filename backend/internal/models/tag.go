@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag es una etiqueta libre que se puede poner a varias transacciones.
+// Ejemplo: "viaje-cartagena", "reembolsable", "trabajo".
+type Tag struct {
+	ID               string    `json:"id"`
+	UserID           string    `json:"user_id"`
+	Name             string    `json:"name"`
+	Color            string    `json:"color"`
+	TransactionCount int       `json:"transaction_count"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CreateTagRequest es lo que el frontend envía para crear una etiqueta.
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=50"`
+	Color string `json:"color" binding:"omitempty,len=7"`
+}
+
+// UpdateTagRequest permite renombrar una etiqueta o cambiar su color.
+type UpdateTagRequest struct {
+	Name  string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color string `json:"color" binding:"omitempty,len=7"`
+}
+
+// TagReport son los totales por etiqueta en un rango de fechas.
+// Una transacción con varias etiquetas suma en cada una.
+type TagReport struct {
+	DateFrom string       `json:"date_from,omitempty"`
+	DateTo   string       `json:"date_to,omitempty"`
+	Tags     []TagSummary `json:"tags"`
+}
+
+// TagSummary es el total de ingresos y gastos de una etiqueta.
+type TagSummary struct {
+	TagID        string  `json:"tag_id"`
+	TagName      string  `json:"tag_name"`
+	TagColor     string  `json:"tag_color"`
+	TotalIncome  float64 `json:"total_income"`
+	TotalExpense float64 `json:"total_expense"`
+	Balance      float64 `json:"balance"`
+	Count        int     `json:"count"`
+}
+
+// NormalizeTagName deja un nombre de etiqueta en su forma guardada:
+// minúsculas, sin espacios a los lados y con guiones en lugar de espacios.
+// Ej: "  Viaje Cartagena " → "viaje-cartagena"
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// NormalizeTagNames normaliza y quita repetidos y vacíos, conservando el orden.
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = NormalizeTagName(n)
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	return out
+}
@@ -2,7 +2,7 @@ package models
 
 import "time"
 
-// Transaction representa un ingreso o gasto registrado por el usuario.
+// Transaction representa un ingreso, gasto o transferencia registrado por el usuario.
 type Transaction struct {
 	ID            string    `json:"id"`
 	UserID        string    `json:"user_id"`
@@ -17,8 +17,29 @@ type Transaction struct {
 	Date          time.Time `json:"-"`        // No se serializa directamente
 	DateStr       string    `json:"date"`     // Se llena manualmente como "2006-01-02"
 	Currency      string    `json:"currency"`
+	RecurringID   *string   `json:"recurring_id,omitempty"` // Plantilla recurrente que la generó (si aplica)
+	ExternalID    *string   `json:"external_id,omitempty"`  // ID del banco (FITID del OFX) para no duplicar al reimportar
+	Tags          []string  `json:"tags"`                   // Nombres de las etiquetas, en orden alfabético
+	Splits        []TransactionSplit `json:"splits,omitempty"` // Divisiones por categoría (vacío = no está dividida)
+
+	// Solo para type "transfer": cuentas de ahorro de origen/destino (nil = flujo principal)
+	FromAccountID   *string `json:"from_account_id,omitempty"`
+	FromAccountName string  `json:"from_account_name,omitempty"`
+	ToAccountID     *string `json:"to_account_id,omitempty"`
+	ToAccountName   string  `json:"to_account_name,omitempty"`
+	TransferKind    string  `json:"transfer_kind,omitempty"` // "to_savings", "from_savings" o "between_savings"
+
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Solo al buscar con q: relevancia del resultado y descripción con los términos
+	// encontrados entre <mark> y </mark>.
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+
+	// Solo al crear: transacciones existentes que parecen la misma (mismo monto, fecha cercana,
+	// descripción parecida). Es un aviso, la transacción sí se crea.
+	PossibleDuplicates []Transaction `json:"possible_duplicates,omitempty"`
 }
 
 // FormatDate convierte Date (time.Time) a DateStr (string "2006-01-02")
@@ -27,13 +48,24 @@ func (t *Transaction) FormatDate() {
 }
 
 // CreateTransactionRequest es lo que el frontend envía para crear una transacción.
+// Si no viene category_id, se asigna con las reglas de categorización.
 type CreateTransactionRequest struct {
-	CategoryID  string  `json:"category_id" binding:"required,uuid"`
+	CategoryID  string  `json:"category_id" binding:"omitempty,uuid"`
 	Amount      float64 `json:"amount" binding:"required,gt=0"`
 	Type        string  `json:"type" binding:"required,oneof=income expense"`
 	Description string  `json:"description" binding:"max=255"`
 	Date        string  `json:"date" binding:"required"` // "2006-01-02"
 	Currency    string  `json:"currency" binding:"omitempty,len=3"`
+	// Tags son nombres de etiquetas; las que no existen se crean.
+	Tags []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+	// Splits reparte el monto entre varias categorías; los montos deben sumar amount.
+	// Sin category_id, la transacción toma la categoría de la división más grande.
+	Splits []SplitRequest `json:"splits" binding:"omitempty,min=2,max=50,dive"`
+
+	// RecurringID lo llena el worker de recurrentes, nunca viene del frontend.
+	RecurringID string `json:"-"`
+	// ExternalID lo llena el importador (ej: FITID del OFX), nunca viene del frontend.
+	ExternalID string `json:"-"`
 }
 
 // UpdateTransactionRequest permite actualizar campos de una transacción.
@@ -44,24 +76,80 @@ type UpdateTransactionRequest struct {
 	Description string  `json:"description" binding:"omitempty,max=255"`
 	Date        string  `json:"date" binding:"omitempty"`
 	Currency    string  `json:"currency" binding:"omitempty,len=3"`
+	// Tags reemplaza las etiquetas: nil = no se tocan, [] = se quitan todas.
+	Tags []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+	// Splits reemplaza las divisiones: nil = no se tocan, [] = deja de estar dividida.
+	Splits []SplitRequest `json:"splits" binding:"omitempty,min=2,max=50,dive"`
+}
+
+// TransactionSplit es una línea de una transacción dividida.
+type TransactionSplit struct {
+	ID            string  `json:"id"`
+	CategoryID    string  `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	CategoryColor string  `json:"category_color"`
+	Amount        float64 `json:"amount"`
+	Note          string  `json:"note"`
+}
+
+// SplitRequest es una línea al crear o reemplazar las divisiones de una transacción.
+type SplitRequest struct {
+	CategoryID string  `json:"category_id" binding:"required,uuid"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	Note       string  `json:"note" binding:"max=255"`
 }
 
 // TransactionFilter contiene los filtros para listar transacciones.
 type TransactionFilter struct {
-	UserID     string
-	Type       string
-	CategoryID string
-	DateFrom   string // "2006-01-02"
-	DateTo     string // "2006-01-02"
-	Page       int
-	Limit      int
+	UserID             string
+	Type               string
+	CategoryIDs        []string // De cualquiera de estas categorías (o con una división en ellas)
+	ExcludeCategoryIDs []string // Sin ninguna de estas categorías
+	MinAmount          *float64
+	MaxAmount          *float64
+	Currency           string
+	DateFrom           string   // "2006-01-02"
+	DateTo             string   // "2006-01-02"
+	Tags               []string // La transacción debe tener todas estas etiquetas
+	Query              string   // Búsqueda de texto en descripción y categoría (sintaxis de buscador web)
+	Sort               string   // Uno de TransactionSortFields ("" = date)
+	Order              string   // "asc" o "desc" ("" = desc, salvo category que es asc)
+	Page               int
+	Limit              int
+
+	// Paginación por cursor (keyset): en vez de Page se lee desde After, que es la última
+	// transacción de la página anterior (nil = primera página). Solo con orden por fecha.
+	Keyset bool
+	After  *TransactionCursor
+	// SkipTotal evita el COUNT(*) del total, que es lo más lento en historiales grandes.
+	SkipTotal bool
 }
 
+// TransactionSortFields son los valores permitidos para TransactionFilter.Sort.
+var TransactionSortFields = []string{"date", "amount", "category", "created_at"}
+
 // TransactionListResponse incluye las transacciones y metadata de paginación.
+// Total y TotalPages se omiten si se pidió sin total; Page, si se paginó por cursor.
 type TransactionListResponse struct {
 	Transactions []Transaction `json:"transactions"`
-	Total        int           `json:"total"`
-	Page         int           `json:"page"`
+	Total        *int          `json:"total,omitempty"`
+	Page         int           `json:"page,omitempty"`
 	Limit        int           `json:"limit"`
-	TotalPages   int           `json:"total_pages"`
+	TotalPages   *int          `json:"total_pages,omitempty"`
+	HasMore      bool          `json:"has_more"`
+	NextCursor   string        `json:"next_cursor,omitempty"` // Para pedir la página siguiente con ?cursor=
+}
+
+// DuplicatePair son dos transacciones que probablemente son la misma.
+type DuplicatePair struct {
+	Transaction Transaction `json:"transaction"`
+	Duplicate   Transaction `json:"duplicate"`
+	DaysApart   int         `json:"days_apart"`
+	Similarity  float64     `json:"similarity"` // Parecido de las descripciones (0 a 1)
+}
+
+// DismissDuplicateRequest marca un par como "no es duplicado".
+type DismissDuplicateRequest struct {
+	TransactionID string `json:"transaction_id" binding:"required,uuid"`
+	DuplicateID   string `json:"duplicate_id" binding:"required,uuid,nefield=TransactionID"`
 }
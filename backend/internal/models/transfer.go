@@ -0,0 +1,25 @@
+package models
+
+// CreateTransferRequest mueve dinero entre el flujo principal y las cuentas de ahorro.
+// Un lado vacío significa "flujo principal". Ejemplo: from="" to="<id Lulo Bank>"
+// pasa dinero del presupuesto mensual a la cuenta de ahorro.
+type CreateTransferRequest struct {
+	FromAccountID string  `json:"from_account_id" binding:"omitempty,uuid"`
+	ToAccountID   string  `json:"to_account_id" binding:"omitempty,uuid"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Description   string  `json:"description" binding:"max=255"`
+	Date          string  `json:"date" binding:"required"` // "2006-01-02"
+	Currency      string  `json:"currency" binding:"omitempty,len=3"`
+}
+
+// TransferKind deduce la dirección de la transferencia a partir de sus lados.
+func (r CreateTransferRequest) TransferKind() string {
+	switch {
+	case r.FromAccountID == "":
+		return "to_savings"
+	case r.ToAccountID == "":
+		return "from_savings"
+	default:
+		return "between_savings"
+	}
+}
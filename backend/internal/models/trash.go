@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Tipos de elemento que pueden estar en la papelera.
+const (
+	TrashTransaction    = "transaction"
+	TrashCategory       = "category"
+	TrashSavingsAccount = "savings_account"
+	TrashBudget         = "budget"
+)
+
+// TrashTypes son los valores válidos de :type en POST /api/trash/:type/:id/restore.
+var TrashTypes = []string{TrashTransaction, TrashCategory, TrashSavingsAccount, TrashBudget}
+
+// TrashItem es un elemento borrado que todavía se puede restaurar.
+// Label es lo que lo identifica para el usuario: la descripción de la transacción,
+// el nombre de la categoría o cuenta, o la categoría y el periodo del presupuesto.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	Amount    *float64  `json:"amount,omitempty"` // Monto, saldo o límite (no aplica a categorías)
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"` // Cuándo se elimina definitivamente
+}
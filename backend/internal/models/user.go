@@ -12,6 +12,7 @@ type User struct {
 	PasswordHash           string    `json:"-"` // El "-" hace que NUNCA se envíe en respuestas JSON
 	Name                   string    `json:"name"`
 	IncludeSavingsInTotal  bool      `json:"include_savings_in_total"` // Si true, ahorros se muestran en el dinero total del dashboard
+	AutoCopyBudgets        bool      `json:"auto_copy_budgets"`        // Si true, al empezar cada periodo se copian los presupuestos del anterior
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
 }
@@ -21,6 +22,9 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Name     string `json:"name" binding:"required,min=2"`
+	// Paquetes de categorías a crear (GET /api/categories/presets); vacío = el predeterminado
+	Presets  []string `json:"presets" binding:"omitempty,max=10,dive,min=1,max=50"`
+	Language string   `json:"language" binding:"omitempty,oneof=es en"` // Idioma de los nombres de categoría (default "es")
 }
 
 // LoginRequest es lo que el frontend envía para hacer login.
@@ -50,11 +54,13 @@ type ResetPasswordRequest struct {
 // UserSettingsResponse es la respuesta de GET /api/user/settings.
 type UserSettingsResponse struct {
 	IncludeSavingsInTotal bool `json:"include_savings_in_total"`
+	AutoCopyBudgets       bool `json:"auto_copy_budgets"`
 }
 
 // UpdateUserSettingsRequest es el body de PATCH /api/user/settings.
 type UpdateUserSettingsRequest struct {
 	IncludeSavingsInTotal *bool `json:"include_savings_in_total"` // puntero para distinguir "no enviado" de false
+	AutoCopyBudgets       *bool `json:"auto_copy_budgets"`
 }
 
 // PasswordReset representa un registro de OTP en la tabla password_resets.
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// SavedView es una combinación de filtros de transacciones guardada con un nombre.
+// Ejemplo: "Restaurantes y taxis del trimestre", "Todo lo reembolsable".
+type SavedView struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	Name      string      `json:"name"`
+	Filters   ViewFilters `json:"filters"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ViewFilters son los filtros de una vista, guardados como JSON. Equivalen a los
+// parámetros de GET /api/transactions, más DateRange para fechas relativas.
+type ViewFilters struct {
+	Type               string   `json:"type,omitempty" binding:"omitempty,oneof=income expense transfer"`
+	CategoryIDs        []string `json:"category_ids,omitempty" binding:"omitempty,max=50,dive,uuid"`
+	ExcludeCategoryIDs []string `json:"exclude_category_ids,omitempty" binding:"omitempty,max=50,dive,uuid"`
+	MinAmount          *float64 `json:"min_amount,omitempty" binding:"omitempty,gte=0"`
+	MaxAmount          *float64 `json:"max_amount,omitempty" binding:"omitempty,gte=0"`
+	Currency           string   `json:"currency,omitempty" binding:"omitempty,len=3"`
+	// DateRange es un rango relativo que se calcula cada vez que se usa la vista
+	// (ej: "this_month"). Si viene, reemplaza a DateFrom y DateTo.
+	DateRange string   `json:"date_range,omitempty" binding:"omitempty,oneof=this_month last_month this_quarter last_quarter this_year last_year year_to_date last_7_days last_30_days last_90_days"`
+	DateFrom  string   `json:"date_from,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	DateTo    string   `json:"date_to,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	Tags      []string `json:"tags,omitempty" binding:"omitempty,max=20,dive,min=1,max=50"`
+	Query     string   `json:"q,omitempty" binding:"omitempty,max=200"`
+	Sort      string   `json:"sort,omitempty" binding:"omitempty,oneof=date amount category created_at"`
+	Order     string   `json:"order,omitempty" binding:"omitempty,oneof=asc desc"`
+}
+
+// CreateViewRequest es lo que el frontend envía para guardar una vista.
+type CreateViewRequest struct {
+	Name    string      `json:"name" binding:"required,min=1,max=100"`
+	Filters ViewFilters `json:"filters"`
+}
+
+// UpdateViewRequest permite renombrar una vista o reemplazar sus filtros.
+type UpdateViewRequest struct {
+	Name    string       `json:"name" binding:"omitempty,min=1,max=100"`
+	Filters *ViewFilters `json:"filters"`
+}
@@ -0,0 +1,191 @@
+// Package ofx lee extractos bancarios en formato OFX/QFX.
+// Soporta OFX 1.x (SGML, las etiquetas de valor no se cierran) y OFX 2.x (XML).
+// Solo se extraen los movimientos (STMTTRN) con lo necesario para importarlos.
+package ofx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Transaction es un movimiento STMTTRN del extracto.
+// El monto se deja como texto: los bancos colombianos no siempre siguen el punto decimal
+// del estándar y el importador lo interpreta con las mismas reglas que un CSV.
+type Transaction struct {
+	FITID     string    // ID único del movimiento dentro de la cuenta
+	TrnType   string    // CREDIT, DEBIT, POS, ATM, FEE... (informativo, el signo lo da Amount)
+	Posted    time.Time // DTPOSTED, solo la fecha
+	Amount    string    // TRNAMT tal como viene (con signo: negativo = sale dinero); lo interpreta el importador
+	Name      string
+	Memo      string
+	Currency  string   // CURDEF del estado de cuenta que contiene el movimiento
+	AccountID string   // ACCTID de la cuenta (puede venir vacío)
+	Errors    []string // Problemas al leer el movimiento (fecha inválida o faltante)
+}
+
+// Statement agrupa los movimientos leídos del archivo.
+type Statement struct {
+	Currency     string // CURDEF del primer estado de cuenta
+	Transactions []Transaction
+}
+
+// ErrNotOFX se devuelve cuando el archivo no tiene la etiqueta <OFX>.
+var ErrNotOFX = errors.New("el archivo no es un extracto OFX válido")
+
+// IsOFX indica si el contenido parece un OFX (encabezado SGML, declaración XML OFX o etiqueta <OFX>).
+func IsOFX(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	upper := bytes.ToUpper(head)
+	return bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>"))
+}
+
+// Parse lee un archivo OFX 1.x o 2.x.
+func Parse(data []byte) (*Statement, error) {
+	text := decode(data)
+
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, ErrNotOFX
+	}
+	text = text[start:]
+
+	stmt := &Statement{}
+	var (
+		currency, accountID string
+		current             *Transaction
+		inAccount           bool
+	)
+
+	for pos := 0; pos < len(text); {
+		open := strings.IndexByte(text[pos:], '<')
+		if open < 0 {
+			break
+		}
+		open += pos
+		end := strings.IndexByte(text[open:], '>')
+		if end < 0 {
+			break
+		}
+		end += open
+		tag := strings.ToUpper(strings.TrimSpace(text[open+1 : end]))
+		pos = end + 1
+
+		// Texto hasta la próxima etiqueta: es el valor si la etiqueta es una hoja
+		next := strings.IndexByte(text[pos:], '<')
+		if next < 0 {
+			next = len(text) - pos
+		}
+		value := strings.TrimSpace(html.UnescapeString(text[pos : pos+next]))
+
+		switch {
+		case strings.HasPrefix(tag, "/"):
+			switch tag[1:] {
+			case "STMTTRN":
+				if current != nil {
+					stmt.Transactions = append(stmt.Transactions, finish(*current))
+					current = nil
+				}
+			case "BANKACCTFROM", "CCACCTFROM":
+				inAccount = false
+			}
+			continue
+		case strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"), strings.HasSuffix(tag, "/"):
+			continue
+		}
+
+		switch tag {
+		case "STMTTRN":
+			// En SGML mal formado puede faltar </STMTTRN>: se cierra el anterior
+			if current != nil {
+				stmt.Transactions = append(stmt.Transactions, finish(*current))
+			}
+			current = &Transaction{Currency: currency, AccountID: accountID}
+			continue
+		case "BANKACCTFROM", "CCACCTFROM":
+			inAccount = true
+			continue
+		case "CURDEF":
+			currency = strings.ToUpper(value)
+			if stmt.Currency == "" {
+				stmt.Currency = currency
+			}
+			continue
+		case "ACCTID":
+			if inAccount {
+				accountID = value
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		switch tag {
+		case "FITID":
+			current.FITID = value
+		case "TRNTYPE":
+			current.TrnType = strings.ToUpper(value)
+		case "DTPOSTED":
+			posted, err := parseDate(value)
+			if err != nil {
+				current.Errors = append(current.Errors, err.Error())
+			}
+			current.Posted = posted
+		case "TRNAMT":
+			current.Amount = value
+		case "NAME", "PAYEE":
+			if current.Name == "" {
+				current.Name = value
+			}
+		case "MEMO":
+			current.Memo = value
+		}
+	}
+
+	if current != nil {
+		stmt.Transactions = append(stmt.Transactions, finish(*current))
+	}
+	return stmt, nil
+}
+
+// finish valida los campos obligatorios de un movimiento ya leído.
+func finish(t Transaction) Transaction {
+	if t.Posted.IsZero() && len(t.Errors) == 0 {
+		t.Errors = append(t.Errors, "el movimiento no tiene DTPOSTED")
+	}
+	return t
+}
+
+// parseDate lee fechas OFX: YYYYMMDD[HHMMSS[.XXX]][[-5:EST]]. Solo interesa la fecha.
+func parseDate(raw string) (time.Time, error) {
+	if len(raw) < 8 {
+		return time.Time{}, fmt.Errorf("DTPOSTED inválido: %q", raw)
+	}
+	t, err := time.Parse("20060102", raw[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("DTPOSTED inválido: %q", raw)
+	}
+	return t, nil
+}
+
+// decode devuelve el archivo como texto UTF-8. Los OFX 1.x suelen venir en
+// Windows-1252/Latin-1 (CHARSET:1252); en ese caso cada byte es una runa.
+func decode(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
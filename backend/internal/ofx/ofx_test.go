@@ -0,0 +1,135 @@
+package ofx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sgmlSample es un OFX 1.x como los que exportan los bancos: encabezado SGML,
+// etiquetas de valor sin cerrar, texto en Windows-1252 y un </STMTTRN> faltante.
+const sgmlSample = "OFXHEADER:100\r\n" +
+	"DATA:OFXSGML\r\n" +
+	"VERSION:102\r\n" +
+	"ENCODING:USASCII\r\n" +
+	"CHARSET:1252\r\n" +
+	"\r\n" +
+	"<OFX>\r\n" +
+	"<BANKMSGSRSV1><STMTTRNRS><STMTRS>\r\n" +
+	"<CURDEF>cop\r\n" +
+	"<BANKACCTFROM><BANKID>007<ACCTID>123456789<ACCTTYPE>SAVINGS</BANKACCTFROM>\r\n" +
+	"<BANKTRANLIST>\r\n" +
+	"<STMTTRN>\r\n" +
+	"<TRNTYPE>debit\r\n" +
+	"<DTPOSTED>20260315120000[-5:COT]\r\n" +
+	"<TRNAMT>-45.000\r\n" +
+	"<FITID>A1\r\n" +
+	"<NAME>Caf\xe9 Juan Valdez\r\n" +
+	"<MEMO>Compra &amp; propina\r\n" +
+	// Falta </STMTTRN>: el siguiente <STMTTRN> cierra el anterior
+	"<STMTTRN>\r\n" +
+	"<TRNTYPE>CREDIT\r\n" +
+	"<DTPOSTED>20260316\r\n" +
+	"<TRNAMT>1500000,50\r\n" +
+	"<FITID>A2\r\n" +
+	"<PAYEE>N\xf3mina\r\n" +
+	"</STMTTRN>\r\n" +
+	"<STMTTRN>\r\n" +
+	"<TRNAMT>10\r\n" +
+	"<FITID>A3\r\n" +
+	"</STMTTRN>\r\n" +
+	"</BANKTRANLIST>\r\n" +
+	"</STMTRS></STMTTRNRS></BANKMSGSRSV1>\r\n" +
+	"</OFX>\r\n"
+
+// xmlSample es un OFX 2.x: XML bien formado, con declaración y etiquetas cerradas.
+const xmlSample = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CURDEF>USD</CURDEF>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>POS</TRNTYPE>
+        <DTPOSTED>20260401</DTPOSTED>
+        <TRNAMT>-12.34</TRNAMT>
+        <FITID>X-1</FITID>
+        <NAME>Coffee</NAME>
+        <MEMO/>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Statement
+	}{
+		{
+			name: "sgml 1.x",
+			data: sgmlSample,
+			want: Statement{
+				Currency: "COP",
+				Transactions: []Transaction{
+					{
+						FITID: "A1", TrnType: "DEBIT", Posted: date(2026, 3, 15), Amount: "-45.000",
+						Name: "Café Juan Valdez", Memo: "Compra & propina", Currency: "COP", AccountID: "123456789",
+					},
+					{
+						FITID: "A2", TrnType: "CREDIT", Posted: date(2026, 3, 16), Amount: "1500000,50",
+						Name: "Nómina", Currency: "COP", AccountID: "123456789",
+					},
+					{
+						FITID: "A3", Amount: "10", Currency: "COP", AccountID: "123456789",
+						Errors: []string{"el movimiento no tiene DTPOSTED"},
+					},
+				},
+			},
+		},
+		{
+			name: "xml 2.x",
+			data: xmlSample,
+			want: Statement{
+				Currency: "USD",
+				Transactions: []Transaction{
+					{
+						FITID: "X-1", TrnType: "POS", Posted: date(2026, 4, 1), Amount: "-12.34",
+						Name: "Coffee", Currency: "USD", AccountID: "4111",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsOFX([]byte(tt.data)) {
+				t.Fatalf("IsOFX = false")
+			}
+			got, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotOFX(t *testing.T) {
+	data := []byte("fecha,descripcion,monto\n2026-03-15,Cafe,-45000\n")
+	if IsOFX(data) {
+		t.Errorf("IsOFX = true for a CSV")
+	}
+	if _, err := Parse(data); err != ErrNotOFX {
+		t.Errorf("Parse error = %v, want ErrNotOFX", err)
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
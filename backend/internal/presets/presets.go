@@ -0,0 +1,175 @@
+// Package presets contiene los paquetes de categorías predeterminadas ("Colombia
+// personal", "Freelancer", "Familia"...). Cada paquete es un archivo JSON en packs/,
+// versionado y con los nombres en cada idioma soportado; se incluyen en el binario.
+//
+// La clave (key) de una categoría identifica el concepto en todos los paquetes: dos
+// paquetes que traen "salud" hablan de la misma categoría y aplicar ambos la crea una
+// sola vez. Al cambiar un paquete se sube su versión.
+package presets
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// DefaultID es el paquete que se aplica cuando el usuario no elige ninguno.
+const DefaultID = "colombia-personal"
+
+// DefaultLanguage es el idioma de respaldo cuando un texto no existe en el pedido.
+const DefaultLanguage = "es"
+
+// Languages son los idiomas en los que están escritos los paquetes.
+var Languages = []string{"es", "en"}
+
+//go:embed packs/*.json
+var packFiles embed.FS
+
+// Text es un texto traducido, por código de idioma.
+type Text map[string]string
+
+// In devuelve el texto en lang, o en DefaultLanguage si no está traducido.
+func (t Text) In(lang string) string {
+	if s := t[lang]; s != "" {
+		return s
+	}
+	return t[DefaultLanguage]
+}
+
+// Category es una categoría de un paquete. Parent es la clave de su categoría padre
+// dentro del mismo paquete ("" = principal); el padre va antes que sus hijas.
+type Category struct {
+	Key    string `json:"key"`
+	Name   Text   `json:"name"`
+	Color  string `json:"color"`
+	Icon   string `json:"icon"`
+	Type   string `json:"type"`
+	Parent string `json:"parent"`
+}
+
+// Pack es un paquete de categorías.
+type Pack struct {
+	ID          string     `json:"id"`
+	Version     int        `json:"version"`
+	Name        Text       `json:"name"`
+	Description Text       `json:"description"`
+	Categories  []Category `json:"categories"`
+}
+
+var (
+	packs   []Pack
+	packsBy map[string]Pack
+)
+
+func init() {
+	var err error
+	packs, err = load(packFiles)
+	if err != nil {
+		panic(err)
+	}
+	packsBy = make(map[string]Pack, len(packs))
+	for _, p := range packs {
+		packsBy[p.ID] = p
+	}
+	if _, ok := packsBy[DefaultID]; !ok {
+		panic(fmt.Sprintf("presets: falta el paquete predeterminado %q", DefaultID))
+	}
+}
+
+// All devuelve todos los paquetes: primero el predeterminado y después por ID.
+func All() []Pack {
+	return packs
+}
+
+// Get busca un paquete por su ID.
+func Get(id string) (Pack, bool) {
+	p, ok := packsBy[id]
+	return p, ok
+}
+
+// SupportedLanguage indica si lang es uno de Languages.
+func SupportedLanguage(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// load lee y valida los paquetes. Un paquete mal escrito es un error de programación:
+// init entra en pánico y el servidor no arranca.
+func load(fsys fs.FS) ([]Pack, error) {
+	files, err := fs.Glob(fsys, "packs/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []Pack
+	ids := make(map[string]bool)
+	keyTypes := make(map[string]string) // clave → tipo, en todos los paquetes
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var p Pack
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("presets: %s: %w", file, err)
+		}
+		if err := validate(p, keyTypes); err != nil {
+			return nil, fmt.Errorf("presets: %s: %w", file, err)
+		}
+		if ids[p.ID] {
+			return nil, fmt.Errorf("presets: %s: id %q repetido", file, p.ID)
+		}
+		ids[p.ID] = true
+		loaded = append(loaded, p)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		if (loaded[i].ID == DefaultID) != (loaded[j].ID == DefaultID) {
+			return loaded[i].ID == DefaultID
+		}
+		return loaded[i].ID < loaded[j].ID
+	})
+	return loaded, nil
+}
+
+func validate(p Pack, keyTypes map[string]string) error {
+	if p.ID == "" || p.Version < 1 || p.Name[DefaultLanguage] == "" {
+		return fmt.Errorf("id, version y name.%s son obligatorios", DefaultLanguage)
+	}
+
+	seen := make(map[string]string) // clave → tipo, en este paquete
+	for _, c := range p.Categories {
+		if c.Key == "" || len(c.Key) > 50 {
+			return fmt.Errorf("clave inválida %q", c.Key)
+		}
+		if _, dup := seen[c.Key]; dup {
+			return fmt.Errorf("clave %q repetida", c.Key)
+		}
+		for _, lang := range Languages {
+			if c.Name[lang] == "" || len(c.Name[lang]) > 100 {
+				return fmt.Errorf("%s: falta el nombre en %q o es muy largo", c.Key, lang)
+			}
+		}
+		if c.Type != "income" && c.Type != "expense" {
+			return fmt.Errorf("%s: tipo inválido %q", c.Key, c.Type)
+		}
+		if len(c.Color) != 7 || c.Icon == "" {
+			return fmt.Errorf("%s: color o ícono inválido", c.Key)
+		}
+		if c.Parent != "" && seen[c.Parent] != c.Type {
+			return fmt.Errorf("%s: el padre %q debe ir antes y ser del mismo tipo", c.Key, c.Parent)
+		}
+		if t, ok := keyTypes[c.Key]; ok && t != c.Type {
+			return fmt.Errorf("%s: la misma clave tiene otro tipo en otro paquete", c.Key)
+		}
+		seen[c.Key] = c.Type
+		keyTypes[c.Key] = c.Type
+	}
+	return nil
+}
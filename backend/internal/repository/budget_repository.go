@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,49 +21,65 @@ func NewBudgetRepository(pool *pgxpool.Pool) *BudgetRepository {
 	return &BudgetRepository{pool: pool}
 }
 
+// budgetSpent es el gasto de la categoría del presupuesto b (y de sus subcategorías) entre
+// sus fechas. Sale de transaction_lines: de una transacción dividida solo cuenta la parte
+// de esta categoría.
+const budgetSpent = `COALESCE(
+	(SELECT SUM(t.amount)
+	 FROM transaction_lines t
+	 WHERE t.user_id = b.user_id
+	   AND t.category_id IN (SELECT id FROM category_descendants(b.category_id))
+	   AND t.type = 'expense'
+	   AND t.date BETWEEN b.start_date AND b.end_date
+	), 0
+)`
+
 // Upsert crea o actualiza un presupuesto (UPSERT = INSERT o UPDATE si ya existe).
-// Usamos ON CONFLICT porque solo puede haber un presupuesto por categoría por mes.
-func (r *BudgetRepository) Upsert(ctx context.Context, userID string, req models.CreateBudgetRequest) (*models.Budget, error) {
+// Usamos ON CONFLICT porque solo puede haber un presupuesto por categoría, tipo de
+// periodo y fecha de inicio. Si el de ese periodo estaba en la papelera, vuelve con el
+// nuevo límite. req.PeriodType, start y end ya vienen resueltos por el service.
+// req.Rollover vacío deja el modo de arrastre que tenía ("none" si es nuevo).
+// Devuelve ErrCategoryNotFound si la categoría no es del usuario o está en la papelera.
+func (r *BudgetRepository) Upsert(ctx context.Context, userID string, req models.CreateBudgetRequest, start, end time.Time) (*models.Budget, error) {
 	b := &models.Budget{}
 	err := r.pool.QueryRow(ctx,
-		`INSERT INTO budgets (user_id, category_id, amount_limit, month, year)
-		 VALUES ($1, $2, $3, $4, $5)
-		 ON CONFLICT (user_id, category_id, month, year)
-		 DO UPDATE SET amount_limit = $3, updated_at = NOW()
-		 RETURNING id, user_id, category_id, amount_limit, month, year, created_at, updated_at`,
-		userID, req.CategoryID, req.AmountLimit, req.Month, req.Year,
-	).Scan(&b.ID, &b.UserID, &b.CategoryID, &b.AmountLimit, &b.Month, &b.Year, &b.CreatedAt, &b.UpdatedAt)
+		`INSERT INTO budgets (user_id, category_id, amount_limit, period_type, start_date, end_date, rollover)
+		 SELECT $1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'none')
+		 WHERE EXISTS (SELECT 1 FROM categories WHERE id = $2 AND user_id = $1 AND deleted_at IS NULL)
+		 ON CONFLICT (user_id, category_id, period_type, start_date)
+		 DO UPDATE SET amount_limit = $3, end_date = $6, rollover = COALESCE(NULLIF($7, ''), budgets.rollover),
+		               deleted_at = NULL, updated_at = NOW()
+		 RETURNING id, user_id, category_id, amount_limit, rollover, period_type, start_date, end_date, created_at, updated_at`,
+		userID, req.CategoryID, req.AmountLimit, req.PeriodType, start, end, req.Rollover,
+	).Scan(&b.ID, &b.UserID, &b.CategoryID, &b.AmountLimit, &b.Rollover, &b.PeriodType, &b.StartDate, &b.EndDate, &b.CreatedAt, &b.UpdatedAt)
 
+	// Sin fila: la categoría no es del usuario o está en la papelera
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error guardando presupuesto: %w", err)
 	}
+	b.FormatDates()
 	return b, nil
 }
 
-// GetByPeriod devuelve los presupuestos de un mes/año con el monto gastado calculado.
-// Esta query es la más compleja: hace JOIN con categories y un subquery para
-// calcular cuánto se ha gastado en cada categoría en ese mes.
-func (r *BudgetRepository) GetByPeriod(ctx context.Context, userID string, month, year int) ([]models.Budget, error) {
+// GetByPeriod devuelve los presupuestos activos en algún día entre from y to (incluidos),
+// de cualquier tipo de periodo, con el monto gastado calculado sobre el periodo de cada
+// uno (ver budgetSpent). El presupuesto de una categoría padre cuenta también el gasto
+// de todas sus subcategorías.
+// Available es el límite más lo arrastrado de los periodos anteriores (ver carriedInto).
+func (r *BudgetRepository) GetByPeriod(ctx context.Context, userID string, from, to time.Time) ([]models.Budget, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT
 			b.id, b.user_id, b.category_id, c.name, c.color, c.icon,
-			b.amount_limit,
-			COALESCE(
-				(SELECT SUM(t.amount)
-				 FROM transactions t
-				 WHERE t.user_id = b.user_id
-				   AND t.category_id = b.category_id
-				   AND t.type = 'expense'
-				   AND EXTRACT(MONTH FROM t.date) = $2
-				   AND EXTRACT(YEAR FROM t.date) = $3
-				), 0
-			) as spent,
-			b.month, b.year, b.created_at, b.updated_at
+			b.amount_limit, b.rollover, `+budgetSpent+` as spent,
+			b.period_type, b.start_date, b.end_date, b.created_at, b.updated_at
 		 FROM budgets b
 		 JOIN categories c ON b.category_id = c.id
-		 WHERE b.user_id = $1 AND b.month = $2 AND b.year = $3
-		 ORDER BY c.name`,
-		userID, month, year,
+		 WHERE b.user_id = $1 AND b.start_date <= $3 AND b.end_date >= $2 AND b.deleted_at IS NULL
+		 ORDER BY c.name, b.start_date, b.period_type`,
+		userID, from, to,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error consultando presupuestos: %w", err)
@@ -71,21 +91,97 @@ func (r *BudgetRepository) GetByPeriod(ctx context.Context, userID string, month
 		var b models.Budget
 		err := rows.Scan(
 			&b.ID, &b.UserID, &b.CategoryID, &b.CategoryName, &b.CategoryColor, &b.CategoryIcon,
-			&b.AmountLimit, &b.Spent, &b.Month, &b.Year, &b.CreatedAt, &b.UpdatedAt,
+			&b.AmountLimit, &b.Rollover, &b.Spent, &b.PeriodType, &b.StartDate, &b.EndDate, &b.CreatedAt, &b.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error leyendo presupuesto: %w", err)
 		}
+		b.FormatDates()
 		budgets = append(budgets, b)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo presupuestos: %w", err)
+	}
+	rows.Close()
+
+	ids := make([]string, len(budgets))
+	for i, b := range budgets {
+		ids[i] = b.ID
+	}
+	carried, err := r.carriedInto(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range budgets {
+		budgets[i].Carried = carried[budgets[i].ID]
+		budgets[i].Available = budgets[i].AmountLimit + budgets[i].Carried
+	}
 
 	return budgets, nil
 }
 
-// Delete elimina un presupuesto.
+// carriedInto calcula cuánto le llega arrastrado a cada presupuesto de ids de sus
+// periodos anteriores, por ID de presupuesto. Se recorre hacia atrás la cadena de
+// presupuestos de la misma categoría y tipo de periodo, cada uno terminando el día antes
+// de que empiece el siguiente (un hueco la corta), y se acumula desde el más antiguo:
+// cada uno pasa al siguiente según su propio modo lo que le quedó de su disponible
+// (límite + lo que recibió) menos lo gastado.
+// Se calcula siempre desde las transacciones, así que refleja las ediciones tardías.
+func (r *BudgetRepository) carriedInto(ctx context.Context, userID string, ids []string) (map[string]float64, error) {
+	carried := make(map[string]float64)
+	if len(ids) == 0 {
+		return carried, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`WITH RECURSIVE chain AS (
+			SELECT b.id AS budget_id, b.id, b.category_id, b.period_type, b.start_date, 0 AS depth
+			FROM budgets b
+			WHERE b.id = ANY($2::uuid[]) AND b.user_id = $1
+			UNION ALL
+			SELECT chain.budget_id, p.id, p.category_id, p.period_type, p.start_date, chain.depth + 1
+			FROM chain
+			JOIN budgets p ON p.user_id = $1 AND p.category_id = chain.category_id
+			 AND p.period_type = chain.period_type
+			 AND p.end_date = chain.start_date - 1
+			 AND p.deleted_at IS NULL
+		)
+		SELECT chain.budget_id, b.amount_limit, b.rollover, `+budgetSpent+` as spent
+		 FROM chain
+		 JOIN budgets b ON b.id = chain.id
+		 WHERE chain.depth > 0
+		 ORDER BY chain.budget_id, chain.depth DESC`,
+		userID, ids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error calculando arrastre de presupuestos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var budgetID, rollover string
+		var limit, spent float64
+		if err := rows.Scan(&budgetID, &limit, &rollover, &spent); err != nil {
+			return nil, fmt.Errorf("error leyendo arrastre de presupuesto: %w", err)
+		}
+		// Las filas vienen del periodo más antiguo al más reciente de cada cadena
+		left := limit + carried[budgetID] - spent
+		switch rollover {
+		case models.BudgetRolloverSurplus:
+			carried[budgetID] = math.Max(left, 0)
+		case models.BudgetRolloverSurplusAndDeficit:
+			carried[budgetID] = left
+		default:
+			carried[budgetID] = 0
+		}
+	}
+	return carried, rows.Err()
+}
+
+// Delete manda un presupuesto a la papelera.
 func (r *BudgetRepository) Delete(ctx context.Context, id, userID string) error {
 	result, err := r.pool.Exec(ctx,
-		`DELETE FROM budgets WHERE id = $1 AND user_id = $2`,
+		`UPDATE budgets SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
 		id, userID,
 	)
 	if err != nil {
@@ -96,3 +192,157 @@ func (r *BudgetRepository) Delete(ctx context.Context, id, userID string) error
 	}
 	return nil
 }
+
+// GetItems devuelve los presupuestos de tipo periodType que empiezan en start como ítems
+// (categoría, límite, tipo de periodo y modo de arrastre), para copiarlos a otro periodo.
+func (r *BudgetRepository) GetItems(ctx context.Context, userID, periodType string, start time.Time) ([]models.BudgetTemplateItem, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT category_id, amount_limit, period_type, rollover
+		 FROM budgets
+		 WHERE user_id = $1 AND period_type = $2 AND start_date = $3 AND deleted_at IS NULL`,
+		userID, periodType, start,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando presupuestos: %w", err)
+	}
+	return scanBudgetItems(rows)
+}
+
+// CreateMany crea los presupuestos de plans, cada uno en su periodo, con los límites
+// multiplicados por factor. Los que ya existen en su periodo solo se reemplazan con
+// overwrite (los que están en la papelera se reemplazan siempre); los de categorías
+// borradas o archivadas se ignoran. Devuelve cuántos se crearon o reemplazaron y cuántos se saltaron.
+func (r *BudgetRepository) CreateMany(ctx context.Context, userID string, plans []models.BudgetPlan, factor float64, overwrite bool) (int, int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creando presupuestos: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := createBudgets(ctx, tx, userID, plans, factor, overwrite)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("error creando presupuestos: %w", err)
+	}
+	return created, len(plans) - created, nil
+}
+
+// PendingAutoCopies devuelve los usuarios con la copia automática activada a los que
+// todavía no se les copió el periodo de tipo periodType que empieza en start.
+func (r *BudgetRepository) PendingAutoCopies(ctx context.Context, periodType string, start time.Time) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.id FROM users u
+		 WHERE u.auto_copy_budgets
+		   AND NOT EXISTS (
+		       SELECT 1 FROM budget_auto_copies a
+		       WHERE a.user_id = u.id AND a.period_type = $1 AND a.start_date = $2
+		   )`,
+		periodType, start,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando copias automáticas pendientes: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("error leyendo usuario: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// AutoCopy copia al periodo [start, end] los presupuestos de tipo periodType del periodo
+// anterior (el que empieza en prevStart), sin tocar los que ya existan, y marca el periodo
+// como copiado. Si otro proceso ya lo marcó no hace nada. Devuelve cuántos presupuestos creó.
+func (r *BudgetRepository) AutoCopy(ctx context.Context, userID, periodType string, prevStart, start, end time.Time) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error copiando presupuestos: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`INSERT INTO budget_auto_copies (user_id, period_type, start_date) VALUES ($1, $2, $3)
+		 ON CONFLICT DO NOTHING`,
+		userID, periodType, start,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error marcando copia automática: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, nil
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT category_id, amount_limit, period_type, rollover
+		 FROM budgets
+		 WHERE user_id = $1 AND period_type = $2 AND start_date = $3 AND deleted_at IS NULL`,
+		userID, periodType, prevStart,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error consultando presupuestos: %w", err)
+	}
+	items, err := scanBudgetItems(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	plans := make([]models.BudgetPlan, len(items))
+	for i, item := range items {
+		plans[i] = models.BudgetPlan{BudgetTemplateItem: item, StartDate: start, EndDate: end}
+	}
+	created, err := createBudgets(ctx, tx, userID, plans, 1, false)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error copiando presupuestos: %w", err)
+	}
+	return created, nil
+}
+
+// scanBudgetItems lee las filas (category_id, amount_limit, period_type, rollover) y las cierra.
+func scanBudgetItems(rows pgx.Rows) ([]models.BudgetTemplateItem, error) {
+	defer rows.Close()
+
+	var items []models.BudgetTemplateItem
+	for rows.Next() {
+		var item models.BudgetTemplateItem
+		if err := rows.Scan(&item.CategoryID, &item.AmountLimit, &item.PeriodType, &item.Rollover); err != nil {
+			return nil, fmt.Errorf("error leyendo presupuesto: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// createBudgets es la parte común de CreateMany y AutoCopy. Devuelve cuántos escribió.
+func createBudgets(ctx context.Context, tx pgx.Tx, userID string, plans []models.BudgetPlan, factor float64, overwrite bool) (int, error) {
+	created := 0
+	for _, plan := range plans {
+		result, err := tx.Exec(ctx,
+			`INSERT INTO budgets (user_id, category_id, amount_limit, period_type, start_date, end_date, rollover)
+			 SELECT $1, c.id, GREATEST(ROUND($3::numeric * $7::numeric, 2), 0.01),
+			        COALESCE(NULLIF($4, ''), 'monthly'), $5, $6, COALESCE(NULLIF($8, ''), 'none')
+			 FROM categories c
+			 WHERE c.id = $2 AND c.user_id = $1 AND c.deleted_at IS NULL AND NOT c.archived
+			 ON CONFLICT (user_id, category_id, period_type, start_date)
+			 DO UPDATE SET amount_limit = EXCLUDED.amount_limit, end_date = EXCLUDED.end_date,
+			               rollover = EXCLUDED.rollover, deleted_at = NULL, updated_at = NOW()
+			 WHERE budgets.deleted_at IS NOT NULL OR $9`,
+			userID, plan.CategoryID, plan.AmountLimit, plan.PeriodType, plan.StartDate, plan.EndDate,
+			factor, plan.Rollover, overwrite,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error creando presupuesto: %w", err)
+		}
+		created += int(result.RowsAffected())
+	}
+	return created, nil
+}
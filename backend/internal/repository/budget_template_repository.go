@@ -0,0 +1,154 @@
+// Repository de plantillas de presupuestos — operaciones SQL sobre budget_templates.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInvalidBudgetCategory se devuelve cuando una plantilla usa una categoría que no es del usuario.
+var ErrInvalidBudgetCategory = errors.New("todas las categorías de la plantilla deben ser tuyas")
+
+const budgetTemplateColumns = `id, user_id, name, items, created_at, updated_at`
+
+type BudgetTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBudgetTemplateRepository(pool *pgxpool.Pool) *BudgetTemplateRepository {
+	return &BudgetTemplateRepository{pool: pool}
+}
+
+// GetAllByUser devuelve las plantillas del usuario en orden alfabético.
+func (r *BudgetTemplateRepository) GetAllByUser(ctx context.Context, userID string) ([]models.BudgetTemplate, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+budgetTemplateColumns+` FROM budget_templates WHERE user_id = $1 ORDER BY name`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando plantillas: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.BudgetTemplate
+	for rows.Next() {
+		t, err := scanBudgetTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo plantilla: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetByID devuelve una plantilla del usuario.
+func (r *BudgetTemplateRepository) GetByID(ctx context.Context, id, userID string) (*models.BudgetTemplate, error) {
+	t, err := scanBudgetTemplate(r.pool.QueryRow(ctx,
+		`SELECT `+budgetTemplateColumns+` FROM budget_templates WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("plantilla no encontrada: %w", err)
+	}
+	return &t, nil
+}
+
+// Create guarda una plantilla. Sus categorías deben ser del usuario.
+func (r *BudgetTemplateRepository) Create(ctx context.Context, userID string, req models.CreateBudgetTemplateRequest) (*models.BudgetTemplate, error) {
+	if err := r.checkCategories(ctx, userID, req.Items); err != nil {
+		return nil, err
+	}
+
+	t, err := scanBudgetTemplate(r.pool.QueryRow(ctx,
+		`INSERT INTO budget_templates (user_id, name, items)
+		 VALUES ($1, $2, $3)
+		 RETURNING `+budgetTemplateColumns,
+		userID, req.Name, req.Items,
+	))
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una plantilla llamada '%s'", req.Name)
+		}
+		return nil, fmt.Errorf("error creando plantilla: %w", err)
+	}
+	return &t, nil
+}
+
+// Update renombra una plantilla y/o reemplaza sus ítems.
+func (r *BudgetTemplateRepository) Update(ctx context.Context, id, userID string, req models.UpdateBudgetTemplateRequest) (*models.BudgetTemplate, error) {
+	if err := r.checkCategories(ctx, userID, req.Items); err != nil {
+		return nil, err
+	}
+
+	// nil = sin cambios (un slice nil se enviaría como JSON null)
+	var items any
+	if req.Items != nil {
+		items = req.Items
+	}
+	t, err := scanBudgetTemplate(r.pool.QueryRow(ctx,
+		`UPDATE budget_templates
+		 SET name = COALESCE(NULLIF($1, ''), name),
+		     items = COALESCE($2, items),
+		     updated_at = NOW()
+		 WHERE id = $3 AND user_id = $4
+		 RETURNING `+budgetTemplateColumns,
+		req.Name, items, id, userID,
+	))
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una plantilla llamada '%s'", req.Name)
+		}
+		return nil, fmt.Errorf("plantilla no encontrada: %w", err)
+	}
+	return &t, nil
+}
+
+// Delete elimina una plantilla del usuario.
+func (r *BudgetTemplateRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM budget_templates WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error eliminando plantilla: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("plantilla no encontrada o no tienes permiso")
+	}
+	return nil
+}
+
+// checkCategories verifica que todas las categorías de items sean del usuario y no
+// estén en la papelera.
+func (r *BudgetTemplateRepository) checkCategories(ctx context.Context, userID string, items []models.BudgetTemplateItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.CategoryID
+	}
+
+	var found int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM categories
+		 WHERE id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NULL`,
+		ids, userID,
+	).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("error consultando categorías: %w", err)
+	}
+	if found != len(ids) {
+		return ErrInvalidBudgetCategory
+	}
+	return nil
+}
+
+func scanBudgetTemplate(row pgx.Row) (models.BudgetTemplate, error) {
+	var t models.BudgetTemplate
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Items, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrBulkTooMany se devuelve cuando el filtro de una operación masiva abarca más
+// transacciones de las permitidas.
+var ErrBulkTooMany = errors.New("el filtro abarca demasiadas transacciones, acótalo")
+
+// ErrBulkCategoryNotFound se devuelve cuando la categoría de set_category no existe o no es del usuario.
+var ErrBulkCategoryNotFound = errors.New("la categoría no existe o no tienes permiso")
+
+// ApplyBulk aplica req.Action a las transacciones ids o, si filter no es nil, a las que
+// cumplen el filtro (máximo maxItems). Todo corre en una sola transacción de base de datos
+// y cada transacción del usuario va en su propio savepoint: si una falla se deshace solo
+// esa y su error queda en el resultado.
+func (r *TransactionRepository) ApplyBulk(ctx context.Context, userID string, ids []string, filter *models.TransactionFilter, req models.BulkTransactionRequest, maxItems int) (*models.BulkResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando operación masiva: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if filter != nil {
+		if ids, err = bulkFilterIDs(ctx, tx, *filter, maxItems); err != nil {
+			return nil, err
+		}
+	}
+
+	// Lo que comparten todas las transacciones se resuelve una sola vez
+	var categoryType, tagID string
+	switch req.Action {
+	case models.BulkSetCategory:
+		err := tx.QueryRow(ctx,
+			`SELECT type FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+			req.CategoryID, userID,
+		).Scan(&categoryType)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBulkCategoryNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error consultando categoría: %w", err)
+		}
+	case models.BulkAddTag:
+		// Si la etiqueta todavía no existe se crea junto con la primera transacción que
+		// la recibe (en su savepoint): si ninguna la recibe, no queda una etiqueta huérfana
+		err := tx.QueryRow(ctx,
+			`SELECT id FROM tags WHERE user_id = $1 AND name = $2`,
+			userID, req.Tag,
+		).Scan(&tagID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("error consultando etiqueta: %w", err)
+		}
+	}
+
+	result := &models.BulkResult{Action: req.Action, Matched: len(ids), Results: make([]models.BulkItemResult, 0, len(ids))}
+	for _, id := range ids {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error iniciando operación masiva: %w", err)
+		}
+
+		createdTag := false
+		if req.Action == models.BulkAddTag && tagID == "" {
+			if tagID, err = createBulkTag(ctx, savepoint, userID, req.Tag); err != nil {
+				return nil, err
+			}
+			createdTag = true
+		}
+
+		itemErr := applyBulkItem(ctx, savepoint, userID, id, req, categoryType, tagID)
+		if itemErr != nil {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, fmt.Errorf("error deshaciendo transacción %s: %w", id, err)
+			}
+			if createdTag {
+				// La etiqueta se deshizo con el savepoint: la siguiente transacción la vuelve a crear
+				tagID = ""
+			}
+			result.Failed++
+			result.Results = append(result.Results, models.BulkItemResult{ID: id, Error: itemErr.Error()})
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("error aplicando operación masiva: %w", err)
+		}
+		result.Succeeded++
+		result.Results = append(result.Results, models.BulkItemResult{ID: id, OK: true})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando operación masiva: %w", err)
+	}
+	return result, nil
+}
+
+// createBulkTag crea la etiqueta de add_tag y devuelve su ID. Si otra petición la creó
+// mientras tanto, devuelve la existente.
+func createBulkTag(ctx context.Context, tx pgx.Tx, userID, name string) (string, error) {
+	var tagID string
+	err := tx.QueryRow(ctx,
+		`INSERT INTO tags (user_id, name) VALUES ($1, $2)
+		 ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		 RETURNING id`,
+		userID, name,
+	).Scan(&tagID)
+	if err != nil {
+		return "", fmt.Errorf("error creando etiqueta: %w", err)
+	}
+	return tagID, nil
+}
+
+// bulkFilterIDs devuelve los IDs de las transacciones que cumplen el filtro, o
+// ErrBulkTooMany si son más de maxItems.
+func bulkFilterIDs(ctx context.Context, tx pgx.Tx, filter models.TransactionFilter, maxItems int) ([]string, error) {
+	where := buildTransactionWhere(filter.UserID, filter)
+	rows, err := tx.Query(ctx,
+		`SELECT t.id FROM transactions t`+where.sql+
+			fmt.Sprintf(" ORDER BY t.date DESC, t.created_at DESC, t.id LIMIT $%d", len(where.args)+1),
+		append(where.args, maxItems+1)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando transacciones del filtro: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error leyendo transacción del filtro: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error consultando transacciones del filtro: %w", err)
+	}
+	if len(ids) > maxItems {
+		return nil, ErrBulkTooMany
+	}
+	return ids, nil
+}
+
+// applyBulkItem aplica la acción a una transacción. Los errores que devuelve son los
+// que ve el usuario en el resultado de esa transacción.
+func applyBulkItem(ctx context.Context, tx pgx.Tx, userID, id string, req models.BulkTransactionRequest, categoryType, tagID string) error {
+	var txType string
+	var hasSplits bool
+	err := tx.QueryRow(ctx,
+		`SELECT t.type, EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+		 FROM transactions t
+		 WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
+		 FOR UPDATE`,
+		id, userID,
+	).Scan(&txType, &hasSplits)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("transacción no encontrada o no tienes permiso")
+	}
+	if err != nil {
+		return fmt.Errorf("error consultando transacción: %w", err)
+	}
+	if txType == "transfer" {
+		return errors.New("las transferencias se modifican desde /api/transfers")
+	}
+
+	switch req.Action {
+	case models.BulkDelete:
+		_, err = tx.Exec(ctx, `UPDATE transactions SET deleted_at = NOW() WHERE id = $1`, id)
+	case models.BulkSetCategory:
+		if hasSplits {
+			return errors.New("la transacción está dividida: cambia la categoría de sus divisiones")
+		}
+		if categoryType != txType {
+			return fmt.Errorf("la categoría es de tipo %s y la transacción de tipo %s", categoryType, txType)
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE transactions SET category_id = $2, updated_at = NOW() WHERE id = $1`,
+			id, req.CategoryID,
+		)
+	case models.BulkAddTag:
+		_, err = tx.Exec(ctx,
+			`INSERT INTO transaction_tags (transaction_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			id, tagID,
+		)
+	case models.BulkSetDate:
+		_, err = tx.Exec(ctx, `UPDATE transactions SET date = $2, updated_at = NOW() WHERE id = $1`, id, req.Date)
+		if IsUniqueViolation(err) {
+			return errors.New("su transacción recurrente ya tiene otra ocurrencia en esa fecha")
+		}
+	case models.BulkSetCurrency:
+		_, err = tx.Exec(ctx, `UPDATE transactions SET currency = $2, updated_at = NOW() WHERE id = $1`, id, req.Currency)
+	default:
+		return fmt.Errorf("acción desconocida: %s", req.Action)
+	}
+	if err != nil {
+		return fmt.Errorf("error aplicando %s: %w", req.Action, err)
+	}
+	return nil
+}
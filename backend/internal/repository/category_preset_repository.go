@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/textutil"
+)
+
+// ApplyPreset crea las categorías de un paquete que el usuario todavía no tiene y anota
+// la versión aplicada. Una categoría ya existe si alguna del usuario (archivada o no,
+// fuera de la papelera) salió de la misma clave de paquete, o si tiene el mismo tipo y
+// su nombre coincide con el de la categoría en cualquier idioma; en ese caso se deja
+// como está y solo se le anota la clave. categories viene con los padres antes que sus hijas.
+// Devuelve las categorías creadas y cuántas ya existían.
+func (r *CategoryRepository) ApplyPreset(ctx context.Context, userID, presetID string, version int, categories []models.PresetCategory) ([]models.Category, int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error aplicando paquete de categorías: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serializa las aplicaciones del mismo usuario para que dos a la vez no dupliquen categorías
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, 0, fmt.Errorf("error aplicando paquete de categorías: %w", err)
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT id, name, type, COALESCE(preset_key, '')
+		 FROM categories
+		 WHERE user_id = $1 AND deleted_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error consultando categorías: %w", err)
+	}
+	byKey := make(map[string]string)  // clave de paquete → id
+	byName := make(map[string]string) // tipo + nombre normalizado → id
+	for rows.Next() {
+		var id, name, catType, key string
+		if err := rows.Scan(&id, &name, &catType, &key); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("error leyendo categoría: %w", err)
+		}
+		if key != "" {
+			byKey[key] = id
+		}
+		if _, ok := byName[catType+"|"+textutil.Normalize(name)]; !ok {
+			byName[catType+"|"+textutil.Normalize(name)] = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error leyendo categorías: %w", err)
+	}
+
+	created := []models.Category{}
+	existing := 0
+	ids := make(map[string]string) // clave → id de la categoría del usuario, para los padres
+	for _, pc := range categories {
+		if id, ok := byKey[pc.Key]; ok {
+			ids[pc.Key] = id
+			existing++
+			continue
+		}
+		if id := matchPresetName(byName, pc); id != "" {
+			if _, err := tx.Exec(ctx,
+				`UPDATE categories SET preset_key = $2 WHERE id = $1 AND preset_key IS NULL`,
+				id, pc.Key,
+			); err != nil {
+				return nil, 0, fmt.Errorf("error actualizando categoría: %w", err)
+			}
+			ids[pc.Key] = id
+			existing++
+			continue
+		}
+
+		cat := models.Category{}
+		err := tx.QueryRow(ctx,
+			`INSERT INTO categories (user_id, name, nickname, color, icon, type, parent_id, preset_key)
+			 VALUES ($1, $2, '', $3, $4, $5, NULLIF($6, '')::uuid, $7)
+			 RETURNING id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at`,
+			userID, pc.Name, pc.Color, pc.Icon, pc.Type, ids[pc.ParentKey], pc.Key,
+		).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error creando categoría '%s': %w", pc.Name, err)
+		}
+		ids[pc.Key] = cat.ID
+		byName[cat.Type+"|"+textutil.Normalize(cat.Name)] = cat.ID
+		created = append(created, cat)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO category_preset_applications (user_id, preset_id, version)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, preset_id) DO UPDATE SET version = EXCLUDED.version, applied_at = NOW()`,
+		userID, presetID, version,
+	); err != nil {
+		return nil, 0, fmt.Errorf("error registrando paquete aplicado: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, fmt.Errorf("error aplicando paquete de categorías: %w", err)
+	}
+	return created, existing, nil
+}
+
+// matchPresetName busca una categoría del usuario con el tipo de pc y uno de sus nombres.
+func matchPresetName(byName map[string]string, pc models.PresetCategory) string {
+	for _, name := range append([]string{pc.Name}, pc.Names...) {
+		if id, ok := byName[pc.Type+"|"+textutil.Normalize(name)]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// GetAppliedPresets devuelve la versión de cada paquete que el usuario aplicó, por ID de paquete.
+func (r *CategoryRepository) GetAppliedPresets(ctx context.Context, userID string) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT preset_id, version FROM category_preset_applications WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando paquetes aplicados: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]int)
+	for rows.Next() {
+		var presetID string
+		var version int
+		if err := rows.Scan(&presetID, &version); err != nil {
+			return nil, fmt.Errorf("error leyendo paquete aplicado: %w", err)
+		}
+		applied[presetID] = version
+	}
+	return applied, rows.Err()
+}
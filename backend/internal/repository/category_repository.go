@@ -2,13 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrCategoryInUse se devuelve al borrar una categoría que todavía usan transacciones,
+// divisiones o recurrentes que no están en la papelera.
+var ErrCategoryInUse = errors.New("la categoría tiene transacciones o recurrentes asociados: usa reassign_to para pasarlos a otra categoría")
+
+// ErrInvalidTargetCategory se devuelve cuando la categoría destino de una reasignación o
+// fusión no es otra categoría activa (no archivada) del usuario.
+var ErrInvalidTargetCategory = errors.New("la categoría destino debe ser otra de tus categorías, sin archivar")
+
+// ErrCategoryTypeMismatch se devuelve al reasignar o fusionar entre una categoría de
+// ingresos y una de gastos.
+var ErrCategoryTypeMismatch = errors.New("las dos categorías deben ser del mismo tipo (ingreso o gasto)")
+
+// ErrCategoryNotFound se devuelve al crear una transacción o un presupuesto con una
+// categoría que no es del usuario o que está en la papelera.
+var ErrCategoryNotFound = errors.New("la categoría no existe, está en la papelera o no tienes permiso")
+
+// ErrInvalidParent se devuelve cuando parent_id no es una categoría del usuario del mismo
+// tipo, o cuando es la categoría misma o una de sus subcategorías.
+var ErrInvalidParent = errors.New("parent_id debe ser otra de tus categorías, del mismo tipo y que no sea una de sus subcategorías")
+
 // CategoryRepository maneja las operaciones de DB para la tabla categories.
 type CategoryRepository struct {
 	pool *pgxpool.Pool
@@ -18,14 +41,15 @@ func NewCategoryRepository(pool *pgxpool.Pool) *CategoryRepository {
 	return &CategoryRepository{pool: pool}
 }
 
-// GetAllByUser devuelve todas las categorías de un usuario.
-func (r *CategoryRepository) GetAllByUser(ctx context.Context, userID string) ([]models.Category, error) {
+// GetAllByUser devuelve las categorías de un usuario. Las archivadas solo se incluyen
+// con includeArchived (sirven para mostrar datos históricos, no para elegir categoría).
+func (r *CategoryRepository) GetAllByUser(ctx context.Context, userID string, includeArchived bool) ([]models.Category, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, user_id, name, nickname, color, icon, type, created_at
+		`SELECT id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at
 		 FROM categories
-		 WHERE user_id = $1
+		 WHERE user_id = $1 AND deleted_at IS NULL AND ($2 OR NOT archived)
 		 ORDER BY type, name`,
-		userID,
+		userID, includeArchived,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error consultando categorías: %w", err)
@@ -35,7 +59,7 @@ func (r *CategoryRepository) GetAllByUser(ctx context.Context, userID string) ([
 	var categories []models.Category
 	for rows.Next() {
 		var cat models.Category
-		err := rows.Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.CreatedAt)
+		err := rows.Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("error leyendo categoría: %w", err)
 		}
@@ -49,11 +73,11 @@ func (r *CategoryRepository) GetAllByUser(ctx context.Context, userID string) ([
 func (r *CategoryRepository) GetByID(ctx context.Context, id, userID string) (*models.Category, error) {
 	cat := &models.Category{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, user_id, name, nickname, color, icon, type, created_at
+		`SELECT id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at
 		 FROM categories
-		 WHERE id = $1 AND user_id = $2`,
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
 		id, userID,
-	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.CreatedAt)
+	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("categoría no encontrada: %w", err)
@@ -61,53 +85,300 @@ func (r *CategoryRepository) GetByID(ctx context.Context, id, userID string) (*m
 	return cat, nil
 }
 
-// Create inserta una nueva categoría.
-func (r *CategoryRepository) Create(ctx context.Context, userID, name, color, icon, catType string) (*models.Category, error) {
+// Create inserta una nueva categoría. parentID es opcional ("" = categoría principal).
+func (r *CategoryRepository) Create(ctx context.Context, userID, name, color, icon, catType, parentID string) (*models.Category, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creando categoría: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if parentID != "" {
+		if err := checkParent(ctx, tx, userID, "", parentID, catType); err != nil {
+			return nil, err
+		}
+	}
+
 	cat := &models.Category{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO categories (user_id, name, nickname, color, icon, type)
-		 VALUES ($1, $2, '', $3, $4, $5)
-		 RETURNING id, user_id, name, nickname, color, icon, type, created_at`,
-		userID, name, color, icon, catType,
-	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.CreatedAt)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO categories (user_id, name, nickname, color, icon, type, parent_id)
+		 VALUES ($1, $2, '', $3, $4, $5, NULLIF($6, '')::uuid)
+		 RETURNING id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at`,
+		userID, name, color, icon, catType, parentID,
+	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("error creando categoría: %w", err)
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error creando categoría: %w", err)
+	}
 	return cat, nil
 }
 
-// Update actualiza los campos de una categoría existente.
-func (r *CategoryRepository) Update(ctx context.Context, id, userID, name, nickname, color, icon string) (*models.Category, error) {
+// Update actualiza los campos de una categoría existente. archived y parentID nil = sin
+// cambios; parentID "" la convierte en categoría principal.
+func (r *CategoryRepository) Update(ctx context.Context, id, userID, name, nickname, color, icon string, archived *bool, parentID *string) (*models.Category, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando categoría: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if parentID != nil && *parentID != "" {
+		var catType string
+		err := tx.QueryRow(ctx,
+			`SELECT type FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+			id, userID,
+		).Scan(&catType)
+		if err != nil {
+			return nil, fmt.Errorf("categoría no encontrada: %w", err)
+		}
+		if err := checkParent(ctx, tx, userID, id, *parentID, catType); err != nil {
+			return nil, err
+		}
+	}
+
 	cat := &models.Category{}
-	err := r.pool.QueryRow(ctx,
+	err = tx.QueryRow(ctx,
 		`UPDATE categories
 		 SET name = COALESCE(NULLIF($3, ''), name),
 		     nickname = $4,
 		     color = COALESCE(NULLIF($5, ''), color),
-		     icon = COALESCE(NULLIF($6, ''), icon)
-		 WHERE id = $1 AND user_id = $2
-		 RETURNING id, user_id, name, nickname, color, icon, type, created_at`,
-		id, userID, name, nickname, color, icon,
-	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.CreatedAt)
+		     icon = COALESCE(NULLIF($6, ''), icon),
+		     archived = COALESCE($7, archived),
+		     parent_id = CASE WHEN $8::text IS NULL THEN parent_id ELSE NULLIF($8, '')::uuid END
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		 RETURNING id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at`,
+		id, userID, name, nickname, color, icon, archived, parentID,
+	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("error actualizando categoría: %w", err)
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error actualizando categoría: %w", err)
+	}
 	return cat, nil
 }
 
-// Delete elimina una categoría. Solo si pertenece al usuario.
-func (r *CategoryRepository) Delete(ctx context.Context, id, userID string) error {
-	result, err := r.pool.Exec(ctx,
-		`DELETE FROM categories WHERE id = $1 AND user_id = $2`,
-		id, userID,
-	)
+// checkParent verifica que parentID pueda ser el padre de la categoría id ("" si es nueva):
+// debe ser del usuario, del mismo tipo y no puede ser la categoría misma ni una de sus
+// subcategorías (eso formaría un ciclo).
+func checkParent(ctx context.Context, tx pgx.Tx, userID, id, parentID, catType string) error {
+	var parentType string
+	var cycle bool
+	err := tx.QueryRow(ctx,
+		`SELECT type, $3 <> '' AND id IN (SELECT id FROM category_descendants(NULLIF($3, '')::uuid))
+		 FROM categories
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		parentID, userID, id,
+	).Scan(&parentType, &cycle)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvalidParent
+	}
+	if err != nil {
+		return fmt.Errorf("error consultando categoría padre: %w", err)
+	}
+	if parentType != catType || cycle {
+		return ErrInvalidParent
+	}
+	return nil
+}
+
+// Delete manda una categoría a la papelera junto con sus presupuestos (con la misma
+// marca de tiempo, para restaurarlos juntos); sus subcategorías suben un nivel.
+// Con reassignTo, antes de borrarla pasa todo lo que la usa a esa otra categoría
+// (ver reassignCategory); sin reassignTo no se puede borrar mientras la usen
+// transacciones, divisiones o recurrentes.
+func (r *CategoryRepository) Delete(ctx context.Context, id, userID, reassignTo string) error {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("error eliminando categoría: %w", err)
 	}
-	if result.RowsAffected() == 0 {
+	defer tx.Rollback(ctx)
+
+	var catType string
+	err = tx.QueryRow(ctx,
+		`SELECT type FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		id, userID,
+	).Scan(&catType)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return fmt.Errorf("categoría no encontrada o no tienes permiso")
 	}
+	if err != nil {
+		return fmt.Errorf("error eliminando categoría: %w", err)
+	}
+
+	if reassignTo != "" {
+		if err := checkTargetCategory(ctx, tx, userID, id, reassignTo, catType); err != nil {
+			return err
+		}
+		if err := reassignCategory(ctx, tx, userID, id, reassignTo); err != nil {
+			return err
+		}
+	}
+
+	var inUse bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM transactions WHERE category_id = $1 AND deleted_at IS NULL)
+		     OR EXISTS (SELECT 1 FROM transaction_splits s JOIN transactions t ON t.id = s.transaction_id
+		                WHERE s.category_id = $1 AND t.deleted_at IS NULL)
+		     OR EXISTS (SELECT 1 FROM recurring_transactions WHERE category_id = $1)`,
+		id,
+	).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("error eliminando categoría: %w", err)
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx,
+		`UPDATE categories SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at`,
+		id,
+	).Scan(&deletedAt)
+	if err != nil {
+		return fmt.Errorf("error eliminando categoría: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE budgets SET deleted_at = $2 WHERE category_id = $1 AND deleted_at IS NULL`,
+		id, deletedAt,
+	); err != nil {
+		return fmt.Errorf("error eliminando presupuestos de la categoría: %w", err)
+	}
+
+	// Sus subcategorías suben un nivel: quedan bajo el padre de la borrada (o como principales)
+	if _, err := tx.Exec(ctx,
+		`UPDATE categories SET parent_id = (SELECT parent_id FROM categories WHERE id = $1)
+		 WHERE parent_id = $1`,
+		id,
+	); err != nil {
+		return fmt.Errorf("error moviendo subcategorías: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error eliminando categoría: %w", err)
+	}
+	return nil
+}
+
+// Merge fusiona la categoría id en targetID: todo lo que usaba id (transacciones, también
+// las de la papelera, divisiones, recurrentes, reglas y presupuestos) pasa a targetID y id
+// se elimina definitivamente. Las etiquetas van con sus transacciones, así que no cambian.
+// Si targetID no viene de un paquete, hereda el preset_key de id.
+// Las subcategorías de id quedan bajo targetID. Devuelve la categoría resultante.
+func (r *CategoryRepository) Merge(ctx context.Context, id, targetID, userID string) (*models.Category, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fusionando categorías: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var catType string
+	err = tx.QueryRow(ctx,
+		`SELECT type FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		id, userID,
+	).Scan(&catType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("categoría no encontrada o no tienes permiso")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fusionando categorías: %w", err)
+	}
+
+	if err := checkTargetCategory(ctx, tx, userID, id, targetID, catType); err != nil {
+		return nil, err
+	}
+	if err := reassignCategory(ctx, tx, userID, id, targetID); err != nil {
+		return nil, err
+	}
+
+	// Si el destino es subcategoría de id, primero sube al nivel de id para no formar un ciclo
+	statements := []string{
+		`UPDATE categories SET parent_id = (SELECT parent_id FROM categories WHERE id = $1)
+		 WHERE id = $2 AND id IN (SELECT id FROM category_descendants($1))`,
+		`UPDATE categories SET parent_id = $2 WHERE parent_id = $1 AND id <> $2`,
+		// El destino hereda el preset_key de id para que volver a aplicar el paquete no la recree
+		`UPDATE categories SET preset_key = (SELECT preset_key FROM categories WHERE id = $1)
+		 WHERE id = $2 AND preset_key IS NULL`,
+		// Solo le quedan presupuestos en la papelera, que se borran en cascada
+		`DELETE FROM categories WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement, id, targetID); err != nil {
+			return nil, fmt.Errorf("error fusionando categorías: %w", err)
+		}
+	}
+
+	cat := &models.Category{}
+	err = tx.QueryRow(ctx,
+		`SELECT id, user_id, name, nickname, color, icon, type, parent_id, archived, created_at
+		 FROM categories WHERE id = $1`,
+		targetID,
+	).Scan(&cat.ID, &cat.UserID, &cat.Name, &cat.Nickname, &cat.Color, &cat.Icon, &cat.Type, &cat.ParentID, &cat.Archived, &cat.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error fusionando categorías: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando fusión de categorías: %w", err)
+	}
+	return cat, nil
+}
+
+// checkTargetCategory verifica que targetID sea otra categoría activa del usuario y del tipo catType.
+func checkTargetCategory(ctx context.Context, tx pgx.Tx, userID, id, targetID, catType string) error {
+	if targetID == id {
+		return ErrInvalidTargetCategory
+	}
+	var targetType string
+	var archived bool
+	err := tx.QueryRow(ctx,
+		`SELECT type, archived FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		targetID, userID,
+	).Scan(&targetType, &archived)
+	if errors.Is(err, pgx.ErrNoRows) || archived {
+		return ErrInvalidTargetCategory
+	}
+	if err != nil {
+		return fmt.Errorf("error consultando categoría destino: %w", err)
+	}
+	if targetType != catType {
+		return ErrCategoryTypeMismatch
+	}
+	return nil
+}
+
+// reassignCategory pasa a targetID todo lo que usa la categoría id: transacciones (también
+// las de la papelera), divisiones, recurrentes y reglas. Los presupuestos se fusionan: si el
+// destino ya tiene presupuesto para ese mismo periodo, los límites se suman.
+func reassignCategory(ctx context.Context, tx pgx.Tx, userID, id, targetID string) error {
+	statements := []string{
+		`UPDATE transactions SET category_id = $2, updated_at = NOW() WHERE category_id = $1 AND user_id = $3`,
+		`UPDATE transaction_splits SET category_id = $2
+		 WHERE category_id = $1 AND transaction_id IN (SELECT id FROM transactions WHERE user_id = $3)`,
+		`UPDATE recurring_transactions SET category_id = $2, updated_at = NOW() WHERE category_id = $1 AND user_id = $3`,
+		`UPDATE categorization_rules SET category_id = $2, updated_at = NOW() WHERE category_id = $1 AND user_id = $3`,
+		// Un presupuesto del destino en la papelera se reemplaza en vez de sumarse
+		`INSERT INTO budgets (user_id, category_id, amount_limit, period_type, start_date, end_date, rollover)
+		 SELECT user_id, $2, amount_limit, period_type, start_date, end_date, rollover
+		 FROM budgets WHERE category_id = $1 AND user_id = $3 AND deleted_at IS NULL
+		 ON CONFLICT (user_id, category_id, period_type, start_date) DO UPDATE
+		 SET amount_limit = CASE WHEN budgets.deleted_at IS NULL
+		                         THEN budgets.amount_limit + EXCLUDED.amount_limit
+		                         ELSE EXCLUDED.amount_limit END,
+		     end_date = CASE WHEN budgets.deleted_at IS NULL THEN budgets.end_date ELSE EXCLUDED.end_date END,
+		     rollover = CASE WHEN budgets.deleted_at IS NULL THEN budgets.rollover ELSE EXCLUDED.rollover END,
+		     deleted_at = NULL, updated_at = NOW()`,
+		`DELETE FROM budgets WHERE category_id = $1 AND user_id = $3 AND deleted_at IS NULL`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement, id, targetID, userID); err != nil {
+			return fmt.Errorf("error reasignando categoría: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxDuplicatePairs limita cuántos pares candidatos se leen en una revisión.
+const maxDuplicatePairs = 1000
+
+// DuplicateRepository busca transacciones que podrían estar repetidas y guarda
+// los pares que el usuario descartó.
+type DuplicateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDuplicateRepository(pool *pgxpool.Pool) *DuplicateRepository {
+	return &DuplicateRepository{pool: pool}
+}
+
+// GetInRange devuelve los ingresos y gastos del usuario entre dos fechas (inclusive).
+// El service compara contra ellos las transacciones nuevas o importadas.
+func (r *DuplicateRepository) GetInRange(ctx context.Context, userID, dateFrom, dateTo string) ([]models.Transaction, error) {
+	rows, err := r.pool.Query(ctx,
+		transactionSelect+transactionJoins+`
+		 WHERE t.user_id = $1 AND t.type IN ('income', 'expense') AND t.deleted_at IS NULL
+		   AND t.date BETWEEN $2 AND $3`,
+		userID, dateFrom, dateTo,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando transacciones para duplicados: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo transacción: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+// GetCandidatePairs devuelve pares de transacciones del usuario con el mismo tipo y monto
+// y fechas a no más de windowDays días, excluyendo los pares descartados.
+// La descripción no se compara aquí: eso lo hace el service.
+// dateFrom y dateTo (opcionales) limitan la fecha de la primera transacción del par.
+func (r *DuplicateRepository) GetCandidatePairs(ctx context.Context, userID string, windowDays int, dateFrom, dateTo string) ([]models.DuplicatePair, error) {
+	query := `SELECT a.id, b.id
+		 FROM transactions a
+		 JOIN transactions b
+		   ON b.user_id = a.user_id
+		  AND b.id > a.id
+		  AND b.amount = a.amount
+		  AND b.type = a.type
+		  AND b.date BETWEEN a.date - $2::int AND a.date + $2::int
+		  AND b.deleted_at IS NULL
+		 WHERE a.user_id = $1 AND a.type IN ('income', 'expense') AND a.deleted_at IS NULL
+		   AND NOT EXISTS (
+		       SELECT 1 FROM duplicate_dismissals d
+		       WHERE d.transaction_a = a.id AND d.transaction_b = b.id
+		   )`
+	args := []interface{}{userID, windowDays}
+	argIndex := 3
+
+	if dateFrom != "" {
+		query += fmt.Sprintf(" AND a.date >= $%d", argIndex)
+		args = append(args, dateFrom)
+		argIndex++
+	}
+	if dateTo != "" {
+		query += fmt.Sprintf(" AND a.date <= $%d", argIndex)
+		args = append(args, dateTo)
+		argIndex++
+	}
+	query += fmt.Sprintf(" ORDER BY a.date DESC, a.id LIMIT $%d", argIndex)
+	args = append(args, maxDuplicatePairs)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando posibles duplicados: %w", err)
+	}
+
+	var ids [][2]string
+	for rows.Next() {
+		var a, b string
+		if err := rows.Scan(&a, &b); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error leyendo posible duplicado: %w", err)
+		}
+		ids = append(ids, [2]string{a, b})
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Cargar las transacciones completas de todos los pares en una sola consulta
+	unique := make([]string, 0, len(ids)*2)
+	for _, pair := range ids {
+		unique = append(unique, pair[0], pair[1])
+	}
+	byID, err := r.getByIDs(ctx, userID, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]models.DuplicatePair, 0, len(ids))
+	for _, pair := range ids {
+		a, okA := byID[pair[0]]
+		b, okB := byID[pair[1]]
+		if !okA || !okB {
+			continue
+		}
+		pairs = append(pairs, models.DuplicatePair{Transaction: a, Duplicate: b})
+	}
+	return pairs, nil
+}
+
+// Dismiss guarda que dos transacciones del usuario no son duplicado.
+func (r *DuplicateRepository) Dismiss(ctx context.Context, userID, transactionID, duplicateID string) error {
+	result, err := r.pool.Exec(ctx,
+		`INSERT INTO duplicate_dismissals (user_id, transaction_a, transaction_b)
+		 SELECT $1, LEAST($2::uuid, $3::uuid), GREATEST($2::uuid, $3::uuid)
+		 WHERE (SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND id IN ($2::uuid, $3::uuid) AND deleted_at IS NULL) = 2
+		 ON CONFLICT DO NOTHING`,
+		userID, transactionID, duplicateID,
+	)
+	if err != nil {
+		return fmt.Errorf("error descartando duplicado: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		// Puede que ya estuviera descartado: solo es error si alguna transacción no existe
+		var count int
+		err := r.pool.QueryRow(ctx,
+			`SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND id IN ($2::uuid, $3::uuid) AND deleted_at IS NULL`,
+			userID, transactionID, duplicateID,
+		).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("error verificando transacciones: %w", err)
+		}
+		if count != 2 {
+			return fmt.Errorf("transacción no encontrada o no tienes permiso")
+		}
+	}
+	return nil
+}
+
+// getByIDs carga transacciones del usuario por ID.
+func (r *DuplicateRepository) getByIDs(ctx context.Context, userID string, ids []string) (map[string]models.Transaction, error) {
+	rows, err := r.pool.Query(ctx,
+		transactionSelect+transactionJoins+`
+		 WHERE t.user_id = $1 AND t.id = ANY($2::uuid[]) AND t.deleted_at IS NULL`,
+		userID, ids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando transacciones: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]models.Transaction, len(ids))
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo transacción: %w", err)
+		}
+		byID[t.ID] = t
+	}
+	return byID, nil
+}
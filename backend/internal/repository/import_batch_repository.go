@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// undoneImportRow es la condición "la transacción (tabla transactions) está en la papelera
+// porque se deshizo su import". Esas filas no cuentan como ya importadas.
+const undoneImportRow = `transactions.deleted_at IS NOT NULL
+	AND transactions.import_batch_id IN (SELECT id FROM import_batches WHERE deleted_at IS NOT NULL)`
+
+// ImportBatchRepository maneja los lotes de importación y sus transacciones.
+type ImportBatchRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewImportBatchRepository(pool *pgxpool.Pool) *ImportBatchRepository {
+	return &ImportBatchRepository{pool: pool}
+}
+
+// GetAllByUser devuelve los lotes del usuario, los más recientes primero.
+func (r *ImportBatchRepository) GetAllByUser(ctx context.Context, userID string) ([]models.ImportBatch, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.id, b.user_id, b.source, b.filename, b.row_count,
+		        (SELECT COUNT(*) FROM transactions t WHERE t.import_batch_id = b.id AND t.deleted_at IS NULL),
+		        b.created_at
+		 FROM import_batches b
+		 WHERE b.user_id = $1 AND b.deleted_at IS NULL
+		 ORDER BY b.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando lotes de importación: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []models.ImportBatch
+	for rows.Next() {
+		var b models.ImportBatch
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Source, &b.Filename, &b.RowCount, &b.TransactionCount, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error leyendo lote de importación: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	return batches, nil
+}
+
+// Create registra el lote e inserta sus transacciones en una sola transacción de base de datos:
+// si alguna falla no se guarda nada. Las que traen un ExternalID ya importado se omiten,
+// salvo que esa transacción esté en la papelera por haberse deshecho su import: en ese
+// caso se reemplaza con la nueva fila y sale de la papelera.
+// Si al final no se insertó ninguna, no se crea el lote y se devuelve nil.
+func (r *ImportBatchRepository) Create(ctx context.Context, userID, source, filename string, reqs []models.CreateTransactionRequest) (*models.ImportBatch, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &models.ImportBatch{}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO import_batches (user_id, source, filename)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, user_id, source, filename, created_at`,
+		userID, source, filename,
+	).Scan(&batch.ID, &batch.UserID, &batch.Source, &batch.Filename, &batch.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creando lote de importación: %w", err)
+	}
+
+	inserted := 0
+	for i, req := range reqs {
+		currency := req.Currency
+		if currency == "" {
+			currency = "COP"
+		}
+		result, err := tx.Exec(ctx,
+			`INSERT INTO transactions (user_id, category_id, amount, type, description, date, currency, external_id, import_batch_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+			 ON CONFLICT (user_id, external_id) WHERE external_id IS NOT NULL
+			 DO UPDATE SET category_id = EXCLUDED.category_id, amount = EXCLUDED.amount, type = EXCLUDED.type,
+			               description = EXCLUDED.description, date = EXCLUDED.date, currency = EXCLUDED.currency,
+			               import_batch_id = EXCLUDED.import_batch_id, deleted_at = NULL, updated_at = NOW()
+			 WHERE `+undoneImportRow,
+			userID, req.CategoryID, req.Amount, req.Type, req.Description, req.Date, currency, req.ExternalID, batch.ID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error insertando transacción %d del lote: %w", i+1, err)
+		}
+		inserted += int(result.RowsAffected())
+	}
+
+	if inserted == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE import_batches SET row_count = $1 WHERE id = $2`, inserted, batch.ID); err != nil {
+		return nil, fmt.Errorf("error actualizando lote de importación: %w", err)
+	}
+	batch.RowCount = inserted
+	batch.TransactionCount = inserted
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando lote de importación: %w", err)
+	}
+	return batch, nil
+}
+
+// Delete deshace un import: manda a la papelera las transacciones del lote que siguen
+// activas (se pueden restaurar) y marca el lote como borrado. Las transacciones conservan
+// su external_id: si el mismo archivo se vuelve a importar, Create las reemplaza.
+// Un import solo crea ingresos y gastos (Create nunca inserta transferencias), así que
+// no hay saldos de ahorro que revertir. Devuelve cuántas transacciones se eliminaron.
+func (r *ImportBatchRepository) Delete(ctx context.Context, id, userID string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var batchID string
+	err = tx.QueryRow(ctx,
+		`SELECT id FROM import_batches WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		id, userID,
+	).Scan(&batchID)
+	if err != nil {
+		return 0, fmt.Errorf("lote de importación no encontrado: %w", err)
+	}
+
+	result, err := tx.Exec(ctx,
+		`UPDATE transactions SET deleted_at = NOW()
+		 WHERE import_batch_id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error eliminando transacciones del lote: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE import_batches SET deleted_at = NOW() WHERE id = $1`, id); err != nil {
+		return 0, fmt.Errorf("error eliminando lote de importación: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error confirmando eliminación del lote: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,252 @@
+// Repository de transacciones recurrentes — operaciones SQL sobre recurring_transactions.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RecurringRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRecurringRepository(pool *pgxpool.Pool) *RecurringRepository {
+	return &RecurringRepository{pool: pool}
+}
+
+// GetAllByUser devuelve las plantillas recurrentes del usuario con los datos de su categoría.
+func (r *RecurringRepository) GetAllByUser(ctx context.Context, userID string) ([]models.RecurringTransaction, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT rt.id, rt.user_id, rt.category_id, c.name, c.color, c.icon,
+			rt.amount, rt.type, rt.description, rt.currency, rt.frequency, rt.day_of_month,
+			rt.start_date, rt.end_date, rt.next_run_date, rt.active, rt.created_at, rt.updated_at
+		 FROM recurring_transactions rt
+		 JOIN categories c ON rt.category_id = c.id
+		 WHERE rt.user_id = $1
+		 ORDER BY rt.next_run_date, rt.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando recurrentes: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.RecurringTransaction
+	for rows.Next() {
+		var rt models.RecurringTransaction
+		err := rows.Scan(
+			&rt.ID, &rt.UserID, &rt.CategoryID, &rt.CategoryName, &rt.CategoryColor, &rt.CategoryIcon,
+			&rt.Amount, &rt.Type, &rt.Description, &rt.Currency, &rt.Frequency, &rt.DayOfMonth,
+			&rt.StartDate, &rt.EndDate, &rt.NextRunDate, &rt.Active, &rt.CreatedAt, &rt.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo recurrente: %w", err)
+		}
+		rt.FormatDates()
+		items = append(items, rt)
+	}
+	return items, nil
+}
+
+// GetByID devuelve una plantilla por su ID, verificando que sea del usuario.
+func (r *RecurringRepository) GetByID(ctx context.Context, id, userID string) (*models.RecurringTransaction, error) {
+	rt := &models.RecurringTransaction{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, user_id, category_id, amount, type, description, currency, frequency, day_of_month,
+			start_date, end_date, next_run_date, active, created_at, updated_at
+		 FROM recurring_transactions
+		 WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	).Scan(
+		&rt.ID, &rt.UserID, &rt.CategoryID, &rt.Amount, &rt.Type, &rt.Description, &rt.Currency, &rt.Frequency, &rt.DayOfMonth,
+		&rt.StartDate, &rt.EndDate, &rt.NextRunDate, &rt.Active, &rt.CreatedAt, &rt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("recurrente no encontrada: %w", err)
+	}
+	rt.FormatDates()
+	return rt, nil
+}
+
+// GetDue devuelve todas las plantillas activas (de todos los usuarios) con ocurrencias pendientes hasta "until".
+func (r *RecurringRepository) GetDue(ctx context.Context, until time.Time) ([]models.RecurringTransaction, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, category_id, amount, type, description, currency, frequency, day_of_month,
+			start_date, end_date, next_run_date, active, created_at, updated_at
+		 FROM recurring_transactions
+		 WHERE active AND next_run_date <= $1
+		   AND (end_date IS NULL OR next_run_date <= end_date)
+		 ORDER BY next_run_date`,
+		until,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando recurrentes pendientes: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.RecurringTransaction
+	for rows.Next() {
+		var rt models.RecurringTransaction
+		err := rows.Scan(
+			&rt.ID, &rt.UserID, &rt.CategoryID, &rt.Amount, &rt.Type, &rt.Description, &rt.Currency, &rt.Frequency, &rt.DayOfMonth,
+			&rt.StartDate, &rt.EndDate, &rt.NextRunDate, &rt.Active, &rt.CreatedAt, &rt.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo recurrente: %w", err)
+		}
+		rt.FormatDates()
+		items = append(items, rt)
+	}
+	return items, nil
+}
+
+// Create inserta una nueva plantilla. nextRun es la primera fecha a materializar.
+func (r *RecurringRepository) Create(ctx context.Context, userID string, req models.CreateRecurringRequest, dayOfMonth *int, nextRun time.Time) (*models.RecurringTransaction, error) {
+	currency := req.Currency
+	if currency == "" {
+		currency = "COP"
+	}
+
+	rt := &models.RecurringTransaction{}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO recurring_transactions
+			(user_id, category_id, amount, type, description, currency, frequency, day_of_month, start_date, end_date, next_run_date)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::date, $11)
+		 RETURNING id, user_id, category_id, amount, type, description, currency, frequency, day_of_month,
+			start_date, end_date, next_run_date, active, created_at, updated_at`,
+		userID, req.CategoryID, req.Amount, req.Type, req.Description, currency, req.Frequency, dayOfMonth,
+		req.StartDate, req.EndDate, nextRun,
+	).Scan(
+		&rt.ID, &rt.UserID, &rt.CategoryID, &rt.Amount, &rt.Type, &rt.Description, &rt.Currency, &rt.Frequency, &rt.DayOfMonth,
+		&rt.StartDate, &rt.EndDate, &rt.NextRunDate, &rt.Active, &rt.CreatedAt, &rt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creando recurrente: %w", err)
+	}
+	rt.FormatDates()
+	return rt, nil
+}
+
+// Update actualiza los campos enviados de una plantilla. Con req.PropagateFrom, en la misma
+// transacción aplica los cambios a las transacciones que ya generó desde esa fecha: si la
+// propagación falla, la plantilla tampoco cambia. Devuelve la plantilla y cuántas
+// transacciones se actualizaron.
+func (r *RecurringRepository) Update(ctx context.Context, id, userID string, req models.UpdateRecurringRequest) (*models.RecurringTransaction, int64, error) {
+	sets := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.CategoryID != "" {
+		sets = append(sets, fmt.Sprintf("category_id = $%d", argIndex))
+		args = append(args, req.CategoryID)
+		argIndex++
+	}
+	if req.Amount > 0 {
+		sets = append(sets, fmt.Sprintf("amount = $%d", argIndex))
+		args = append(args, req.Amount)
+		argIndex++
+	}
+	if req.Description != "" {
+		sets = append(sets, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, req.Description)
+		argIndex++
+	}
+	if req.Currency != "" {
+		sets = append(sets, fmt.Sprintf("currency = $%d", argIndex))
+		args = append(args, req.Currency)
+		argIndex++
+	}
+	if req.EndDate != nil {
+		sets = append(sets, fmt.Sprintf("end_date = NULLIF($%d, '')::date", argIndex))
+		args = append(args, *req.EndDate)
+		argIndex++
+	}
+	if req.Active != nil {
+		sets = append(sets, fmt.Sprintf("active = $%d", argIndex))
+		args = append(args, *req.Active)
+		argIndex++
+	}
+
+	if len(sets) == 0 {
+		return nil, 0, fmt.Errorf("no se proporcionaron campos para actualizar")
+	}
+
+	sets = append(sets, "updated_at = NOW()")
+
+	query := fmt.Sprintf(
+		`UPDATE recurring_transactions SET %s WHERE id = $%d AND user_id = $%d
+		 RETURNING id, user_id, category_id, amount, type, description, currency, frequency, day_of_month,
+			start_date, end_date, next_run_date, active, created_at, updated_at`,
+		strings.Join(sets, ", "), argIndex, argIndex+1,
+	)
+	args = append(args, id, userID)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error actualizando recurrente: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rt := &models.RecurringTransaction{}
+	err = tx.QueryRow(ctx, query, args...).Scan(
+		&rt.ID, &rt.UserID, &rt.CategoryID, &rt.Amount, &rt.Type, &rt.Description, &rt.Currency, &rt.Frequency, &rt.DayOfMonth,
+		&rt.StartDate, &rt.EndDate, &rt.NextRunDate, &rt.Active, &rt.CreatedAt, &rt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error actualizando recurrente: %w", err)
+	}
+	rt.FormatDates()
+
+	var propagated int64
+	if req.PropagateFrom != "" {
+		propagated, err = updateGeneratedFrom(ctx, tx, userID, id, req.PropagateFrom, models.UpdateTransactionRequest{
+			CategoryID:  req.CategoryID,
+			Amount:      req.Amount,
+			Description: req.Description,
+			Currency:    req.Currency,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, fmt.Errorf("error actualizando recurrente: %w", err)
+	}
+	return rt, propagated, nil
+}
+
+// SetNextRun avanza la próxima fecha a materializar de una plantilla.
+// Solo avanza hacia adelante, así dos workers simultáneos no retroceden la fecha.
+func (r *RecurringRepository) SetNextRun(ctx context.Context, id string, next time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE recurring_transactions SET next_run_date = $2
+		 WHERE id = $1 AND next_run_date < $2`,
+		id, next,
+	)
+	if err != nil {
+		return fmt.Errorf("error avanzando recurrente: %w", err)
+	}
+	return nil
+}
+
+// Delete elimina una plantilla. Las transacciones ya generadas se conservan (recurring_id queda NULL).
+func (r *RecurringRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM recurring_transactions WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error eliminando recurrente: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("recurrente no encontrada o no tienes permiso")
+	}
+	return nil
+}
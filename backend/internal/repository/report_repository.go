@@ -19,23 +19,32 @@ func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
 
 // GetMonthlySummary calcula el resumen financiero de un mes.
 // Usa SUM + GROUP BY para obtener totales por categoría directamente en SQL.
-func (r *ReportRepository) GetMonthlySummary(ctx context.Context, userID string, month, year int) (*models.MonthlySummary, error) {
+// scope (opcional, ej: una vista guardada) limita las transacciones que se suman.
+// Con rollup, el desglose trae solo categorías principales con el total de sus subcategorías.
+func (r *ReportRepository) GetMonthlySummary(ctx context.Context, userID string, month, year int, scope *models.TransactionFilter, rollup bool) (*models.MonthlySummary, error) {
 	summary := &models.MonthlySummary{
 		Month: month,
 		Year:  year,
 	}
 
-	// Obtener totales generales (ingresos y gastos del mes)
+	// $1 es userID; el mes y el año van después de los parámetros del scope
+	scopeSQL, args := transactionScope(userID, "t.transaction_id", "t.category_id", scope)
+	period := fmt.Sprintf("EXTRACT(MONTH FROM t.date) = $%d AND EXTRACT(YEAR FROM t.date) = $%d", len(args)+1, len(args)+2)
+	args = append(args, month, year)
+
+	// Obtener totales generales (ingresos y gastos del mes).
+	// Las transferencias no son ingreso ni gasto: se reportan aparte.
 	err := r.pool.QueryRow(ctx,
 		`SELECT
-			COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as total_expense
-		 FROM transactions
-		 WHERE user_id = $1
-		   AND EXTRACT(MONTH FROM date) = $2
-		   AND EXTRACT(YEAR FROM date) = $3`,
-		userID, month, year,
-	).Scan(&summary.TotalIncome, &summary.TotalExpense)
+			COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as total_expense,
+			COALESCE(SUM(CASE WHEN t.transfer_kind = 'to_savings' THEN t.amount ELSE 0 END), 0) as to_savings,
+			COALESCE(SUM(CASE WHEN t.transfer_kind = 'from_savings' THEN t.amount ELSE 0 END), 0) as from_savings
+		 FROM transaction_lines t
+		 WHERE t.user_id = $1
+		   AND `+period+scopeSQL,
+		args...,
+	).Scan(&summary.TotalIncome, &summary.TotalExpense, &summary.TransfersToSavings, &summary.TransfersFromSavings)
 
 	if err != nil {
 		return nil, fmt.Errorf("error calculando totales mensuales: %w", err)
@@ -43,17 +52,29 @@ func (r *ReportRepository) GetMonthlySummary(ctx context.Context, userID string,
 
 	summary.Balance = summary.TotalIncome - summary.TotalExpense
 
-	// Obtener desglose por categoría
+	// Obtener desglose por categoría. Se lee de transaction_lines para que cada
+	// división de una transacción dividida sume en su propia categoría.
+	// category_roots asocia cada categoría consigo misma o, con rollup, con su categoría principal.
+	roots := `SELECT id, id AS root_id FROM categories WHERE user_id = $1`
+	if rollup {
+		roots = `WITH RECURSIVE tree AS (
+			    SELECT id, id AS root_id FROM categories WHERE user_id = $1 AND parent_id IS NULL
+			    UNION
+			    SELECT c.id, tree.root_id FROM categories c JOIN tree ON c.parent_id = tree.id
+			 )
+			 SELECT id, root_id FROM tree`
+	}
 	rows, err := r.pool.Query(ctx,
-		`SELECT c.id, c.name, c.color, t.type, SUM(t.amount) as total
-		 FROM transactions t
-		 JOIN categories c ON t.category_id = c.id
+		`SELECT c.id, c.name, c.color, c.parent_id, t.type, SUM(t.amount) as total
+		 FROM transaction_lines t
+		 JOIN (`+roots+`) category_roots ON category_roots.id = t.category_id
+		 JOIN categories c ON c.id = category_roots.root_id
 		 WHERE t.user_id = $1
-		   AND EXTRACT(MONTH FROM t.date) = $2
-		   AND EXTRACT(YEAR FROM t.date) = $3
-		 GROUP BY c.id, c.name, c.color, t.type
+		   AND t.type IN ('income', 'expense')
+		   AND `+period+scopeSQL+`
+		 GROUP BY c.id, c.name, c.color, c.parent_id, t.type
 		 ORDER BY total DESC`,
-		userID, month, year,
+		args...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error consultando resumen por categoría: %w", err)
@@ -62,7 +83,7 @@ func (r *ReportRepository) GetMonthlySummary(ctx context.Context, userID string,
 
 	for rows.Next() {
 		var cs models.CategorySummary
-		err := rows.Scan(&cs.CategoryID, &cs.CategoryName, &cs.CategoryColor, &cs.Type, &cs.Total)
+		err := rows.Scan(&cs.CategoryID, &cs.CategoryName, &cs.CategoryColor, &cs.ParentID, &cs.Type, &cs.Total)
 		if err != nil {
 			return nil, fmt.Errorf("error leyendo resumen de categoría: %w", err)
 		}
@@ -77,20 +98,23 @@ func (r *ReportRepository) GetMonthlySummary(ctx context.Context, userID string,
 }
 
 // GetYearlySummary calcula el resumen de un año completo, mes a mes.
-// Genera una fila por cada mes que tenga transacciones.
-func (r *ReportRepository) GetYearlySummary(ctx context.Context, userID string, year int) (*models.YearlySummary, error) {
+// Genera una fila por cada mes que tenga transacciones. scope es opcional.
+func (r *ReportRepository) GetYearlySummary(ctx context.Context, userID string, year int, scope *models.TransactionFilter) (*models.YearlySummary, error) {
 	summary := &models.YearlySummary{Year: year}
 
+	scopeSQL, args := transactionScope(userID, "t.transaction_id", "t.category_id", scope)
+	args = append(args, year)
+
 	rows, err := r.pool.Query(ctx,
 		`SELECT
-			EXTRACT(MONTH FROM date)::int as month,
-			COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as total_expense
-		 FROM transactions
-		 WHERE user_id = $1 AND EXTRACT(YEAR FROM date) = $2
-		 GROUP BY EXTRACT(MONTH FROM date)
+			EXTRACT(MONTH FROM t.date)::int as month,
+			COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0) as total_expense
+		 FROM transaction_lines t
+		 WHERE t.user_id = $1 AND EXTRACT(YEAR FROM t.date) = $`+fmt.Sprint(len(args))+scopeSQL+`
+		 GROUP BY EXTRACT(MONTH FROM t.date)
 		 ORDER BY month`,
-		userID, year,
+		args...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error consultando resumen anual: %w", err)
@@ -117,3 +141,47 @@ func (r *ReportRepository) GetYearlySummary(ctx context.Context, userID string,
 
 	return summary, nil
 }
+
+// GetTagTotals suma ingresos y gastos por etiqueta entre dos fechas (opcionales).
+// scope (opcional) limita las transacciones que se suman.
+func (r *ReportRepository) GetTagTotals(ctx context.Context, userID, dateFrom, dateTo string, scope *models.TransactionFilter) ([]models.TagSummary, error) {
+	query := `SELECT tg.id, tg.name, tg.color,
+		        COALESCE(SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN t.type = 'expense' THEN t.amount ELSE 0 END), 0),
+		        COUNT(t.id)
+		 FROM tags tg
+		 JOIN transaction_tags tt ON tt.tag_id = tg.id
+		 JOIN transactions t ON t.id = tt.transaction_id AND t.type IN ('income', 'expense') AND t.deleted_at IS NULL
+		 WHERE tg.user_id = $1`
+	scopeSQL, args := transactionScope(userID, "t.id", "", scope)
+	query += scopeSQL
+	argIndex := len(args) + 1
+
+	if dateFrom != "" {
+		query += fmt.Sprintf(" AND t.date >= $%d", argIndex)
+		args = append(args, dateFrom)
+		argIndex++
+	}
+	if dateTo != "" {
+		query += fmt.Sprintf(" AND t.date <= $%d", argIndex)
+		args = append(args, dateTo)
+	}
+	query += ` GROUP BY tg.id, tg.name, tg.color ORDER BY 5 DESC, tg.name`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando totales por etiqueta: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []models.TagSummary
+	for rows.Next() {
+		var ts models.TagSummary
+		if err := rows.Scan(&ts.TagID, &ts.TagName, &ts.TagColor, &ts.TotalIncome, &ts.TotalExpense, &ts.Count); err != nil {
+			return nil, fmt.Errorf("error leyendo total por etiqueta: %w", err)
+		}
+		ts.Balance = ts.TotalIncome - ts.TotalExpense
+		totals = append(totals, ts)
+	}
+	return totals, nil
+}
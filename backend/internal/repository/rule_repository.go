@@ -0,0 +1,207 @@
+// Repository de reglas de categorización — operaciones SQL sobre categorization_rules.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ruleSelect lee una regla con el nombre y color de su categoría (alias r y c).
+const ruleSelect = `SELECT r.id, r.user_id, r.category_id, c.name, c.color, r.name, r.match_type, r.pattern,
+		r.min_amount, r.max_amount, r.type, r.priority, r.active, r.created_at, r.updated_at
+	 FROM categorization_rules r
+	 JOIN categories c ON r.category_id = c.id`
+
+type RuleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRuleRepository(pool *pgxpool.Pool) *RuleRepository {
+	return &RuleRepository{pool: pool}
+}
+
+// GetAllByUser devuelve las reglas del usuario en el orden en que se evalúan.
+// Con onlyActive se omiten las desactivadas y las de categorías archivadas o en la papelera.
+func (r *RuleRepository) GetAllByUser(ctx context.Context, userID string, onlyActive bool) ([]models.CategorizationRule, error) {
+	query := ruleSelect + ` WHERE r.user_id = $1`
+	if onlyActive {
+		query += ` AND r.active AND NOT c.archived AND c.deleted_at IS NULL`
+	}
+	query += ` ORDER BY r.priority, r.created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando reglas: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.CategorizationRule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo regla: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// GetByID devuelve una regla del usuario.
+func (r *RuleRepository) GetByID(ctx context.Context, id, userID string) (*models.CategorizationRule, error) {
+	rule, err := scanRule(r.pool.QueryRow(ctx, ruleSelect+` WHERE r.id = $1 AND r.user_id = $2`, id, userID))
+	if err != nil {
+		return nil, fmt.Errorf("regla no encontrada: %w", err)
+	}
+	return &rule, nil
+}
+
+// Create inserta una regla. ruleType es el tipo de la categoría asignada.
+func (r *RuleRepository) Create(ctx context.Context, userID string, req models.CreateRuleRequest, ruleType string) (*models.CategorizationRule, error) {
+	matchType := req.MatchType
+	if matchType == "" {
+		matchType = "contains"
+	}
+	priority := 100
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO categorization_rules (user_id, category_id, name, match_type, pattern, min_amount, max_amount, type, priority, active)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING id`,
+		userID, req.CategoryID, req.Name, matchType, req.Pattern, req.MinAmount, req.MaxAmount, ruleType, priority, active,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("error creando regla: %w", err)
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// Update modifica los campos enviados. ruleType se actualiza junto con la categoría.
+func (r *RuleRepository) Update(ctx context.Context, id, userID string, req models.UpdateRuleRequest, ruleType string) (*models.CategorizationRule, error) {
+	sets := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	add := func(column string, value interface{}) {
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	if req.Name != "" {
+		add("name", req.Name)
+	}
+	if req.CategoryID != "" {
+		add("category_id", req.CategoryID)
+		add("type", ruleType)
+	}
+	if req.MatchType != "" {
+		add("match_type", req.MatchType)
+	}
+	if req.Pattern != "" {
+		add("pattern", req.Pattern)
+	}
+	// 0 quita el límite
+	if req.MinAmount != nil {
+		sets = append(sets, fmt.Sprintf("min_amount = NULLIF($%d::numeric, 0)", argIndex))
+		args = append(args, *req.MinAmount)
+		argIndex++
+	}
+	if req.MaxAmount != nil {
+		sets = append(sets, fmt.Sprintf("max_amount = NULLIF($%d::numeric, 0)", argIndex))
+		args = append(args, *req.MaxAmount)
+		argIndex++
+	}
+	if req.Priority != nil {
+		add("priority", *req.Priority)
+	}
+	if req.Active != nil {
+		add("active", *req.Active)
+	}
+
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("no se proporcionaron campos para actualizar")
+	}
+	sets = append(sets, "updated_at = NOW()")
+
+	query := fmt.Sprintf(
+		`UPDATE categorization_rules SET %s WHERE id = $%d AND user_id = $%d`,
+		strings.Join(sets, ", "), argIndex, argIndex+1,
+	)
+	args = append(args, id, userID)
+
+	result, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando regla: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("regla no encontrada o no tienes permiso")
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// Delete elimina una regla del usuario.
+func (r *RuleRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM categorization_rules WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error eliminando regla: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("regla no encontrada o no tienes permiso")
+	}
+	return nil
+}
+
+// ApplyChanges cambia la categoría de varias transacciones en una sola transacción de base de datos.
+// Devuelve cuántas se actualizaron.
+func (r *RuleRepository) ApplyChanges(ctx context.Context, userID string, changes []models.RuleChange) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updated := 0
+	for _, change := range changes {
+		result, err := tx.Exec(ctx,
+			`UPDATE transactions SET category_id = $1, updated_at = NOW()
+			 WHERE id = $2 AND user_id = $3 AND type <> 'transfer' AND deleted_at IS NULL`,
+			change.ToCategoryID, change.TransactionID, userID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("error recategorizando transacción: %w", err)
+		}
+		updated += int(result.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error confirmando recategorización: %w", err)
+	}
+	return updated, nil
+}
+
+func scanRule(row pgx.Row) (models.CategorizationRule, error) {
+	var rule models.CategorizationRule
+	err := row.Scan(
+		&rule.ID, &rule.UserID, &rule.CategoryID, &rule.CategoryName, &rule.CategoryColor,
+		&rule.Name, &rule.MatchType, &rule.Pattern, &rule.MinAmount, &rule.MaxAmount,
+		&rule.Type, &rule.Priority, &rule.Active, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	return rule, err
+}
@@ -1,15 +1,23 @@
 // Repository de cuentas de ahorro — operaciones SQL puras.
+// Todo cambio de saldo pasa por recordSavingsDelta, que además deja
+// una fila en savings_movements dentro de la misma transacción.
 package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// savingsColumns son las columnas que se leen de savings_accounts.
+const savingsColumns = `id, user_id, name, balance, color, icon, notes, created_at, updated_at, target_amount, target_date`
+
 type SavingsRepository struct {
 	pool *pgxpool.Pool
 }
@@ -18,27 +26,40 @@ func NewSavingsRepository(pool *pgxpool.Pool) *SavingsRepository {
 	return &SavingsRepository{pool: pool}
 }
 
-// Create inserta una nueva cuenta de ahorro.
-func (r *SavingsRepository) Create(ctx context.Context, userID, name string, balance float64, color, icon, notes string) (*models.SavingsAccount, error) {
-	acc := &models.SavingsAccount{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO savings_accounts (user_id, name, balance, color, icon, notes)
-		 VALUES ($1, $2, $3, $4, $5, $6)
-		 RETURNING id, user_id, name, balance, color, icon, notes, created_at, updated_at`,
-		userID, name, balance, color, icon, notes,
-	).Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt)
+// Create inserta una nueva cuenta de ahorro y registra su saldo inicial en el historial.
+// targetAmount y targetDate son la meta opcional (nil = sin meta).
+func (r *SavingsRepository) Create(ctx context.Context, userID, name string, balance float64, color, icon, notes string, targetAmount *float64, targetDate *string) (*models.SavingsAccount, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creando cuenta de ahorro: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
+	acc, err := scanSavingsAccount(tx.QueryRow(ctx,
+		`INSERT INTO savings_accounts (user_id, name, balance, color, icon, notes, target_amount, target_date)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::date)
+		 RETURNING `+savingsColumns,
+		userID, name, balance, color, icon, notes, targetAmount, targetDate,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("error creando cuenta de ahorro: %w", err)
 	}
+
+	if err := insertSavingsMovement(ctx, tx, acc.ID, userID, "initial", balance, balance, "Saldo inicial", "", ""); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error creando cuenta de ahorro: %w", err)
+	}
 	return acc, nil
 }
 
 // GetAllByUser devuelve todas las cuentas de ahorro de un usuario.
 func (r *SavingsRepository) GetAllByUser(ctx context.Context, userID string) ([]models.SavingsAccount, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, user_id, name, balance, color, icon, notes, created_at, updated_at
-		 FROM savings_accounts WHERE user_id = $1
+		`SELECT `+savingsColumns+`
+		 FROM savings_accounts WHERE user_id = $1 AND deleted_at IS NULL
 		 ORDER BY created_at ASC`,
 		userID,
 	)
@@ -49,68 +70,104 @@ func (r *SavingsRepository) GetAllByUser(ctx context.Context, userID string) ([]
 
 	var accounts []models.SavingsAccount
 	for rows.Next() {
-		var acc models.SavingsAccount
-		if err := rows.Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
+		acc, err := scanSavingsAccount(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error escaneando cuenta de ahorro: %w", err)
 		}
-		accounts = append(accounts, acc)
+		accounts = append(accounts, *acc)
 	}
 	return accounts, nil
 }
 
 // GetByID devuelve una cuenta de ahorro por su ID (verificando que sea del usuario).
 func (r *SavingsRepository) GetByID(ctx context.Context, id, userID string) (*models.SavingsAccount, error) {
-	acc := &models.SavingsAccount{}
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, user_id, name, balance, color, icon, notes, created_at, updated_at
-		 FROM savings_accounts WHERE id = $1 AND user_id = $2`,
+	acc, err := scanSavingsAccount(r.pool.QueryRow(ctx,
+		`SELECT `+savingsColumns+`
+		 FROM savings_accounts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
 		id, userID,
-	).Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt)
-
+	))
 	if err != nil {
 		return nil, fmt.Errorf("cuenta de ahorro no encontrada: %w", err)
 	}
 	return acc, nil
 }
 
-// Update actualiza una cuenta de ahorro.
-func (r *SavingsRepository) Update(ctx context.Context, id, userID, name string, balance float64, color, icon, notes string) (*models.SavingsAccount, error) {
-	acc := &models.SavingsAccount{}
-	err := r.pool.QueryRow(ctx,
+// Update actualiza una cuenta de ahorro (incluida su meta; nil = sin meta).
+// balance nil deja el saldo como está; si cambia, la diferencia queda registrada como
+// corrección manual ("adjustment").
+func (r *SavingsRepository) Update(ctx context.Context, id, userID, name string, balance *float64, color, icon, notes string, targetAmount *float64, targetDate *string) (*models.SavingsAccount, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando cuenta de ahorro: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current float64
+	err = tx.QueryRow(ctx,
+		`SELECT balance FROM savings_accounts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		id, userID,
+	).Scan(&current)
+	if err != nil {
+		return nil, fmt.Errorf("cuenta de ahorro no encontrada: %w", err)
+	}
+
+	if balance != nil && *balance != current {
+		delta := *balance - current
+		if err := recordSavingsDelta(ctx, tx, id, userID, delta, "adjustment", "Corrección manual del saldo", "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	acc, err := scanSavingsAccount(tx.QueryRow(ctx,
 		`UPDATE savings_accounts
-		 SET name = $3, balance = $4, color = $5, icon = $6, notes = $7, updated_at = NOW()
+		 SET name = $3, color = $4, icon = $5, notes = $6,
+		     target_amount = $7, target_date = $8::date, updated_at = NOW()
 		 WHERE id = $1 AND user_id = $2
-		 RETURNING id, user_id, name, balance, color, icon, notes, created_at, updated_at`,
-		id, userID, name, balance, color, icon, notes,
-	).Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt)
-
+		 RETURNING `+savingsColumns,
+		id, userID, name, color, icon, notes, targetAmount, targetDate,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("error actualizando cuenta de ahorro: %w", err)
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error actualizando cuenta de ahorro: %w", err)
+	}
 	return acc, nil
 }
 
-// AdjustBalance suma o resta dinero al balance de una cuenta.
-func (r *SavingsRepository) AdjustBalance(ctx context.Context, id, userID string, amount float64) (*models.SavingsAccount, error) {
-	acc := &models.SavingsAccount{}
-	err := r.pool.QueryRow(ctx,
-		`UPDATE savings_accounts
-		 SET balance = balance + $3, updated_at = NOW()
-		 WHERE id = $1 AND user_id = $2
-		 RETURNING id, user_id, name, balance, color, icon, notes, created_at, updated_at`,
-		id, userID, amount,
-	).Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt)
+// AdjustBalance suma o resta dinero al balance de una cuenta y lo registra en el historial.
+// movementType es "deposit" o "withdraw".
+func (r *SavingsRepository) AdjustBalance(ctx context.Context, id, userID string, amount float64, movementType, note string) (*models.SavingsAccount, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error ajustando balance: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := recordSavingsDelta(ctx, tx, id, userID, amount, movementType, note, "", ""); err != nil {
+		return nil, err
+	}
 
+	acc, err := scanSavingsAccount(tx.QueryRow(ctx,
+		`SELECT `+savingsColumns+` FROM savings_accounts WHERE id = $1`,
+		id,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("error ajustando balance: %w", err)
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error ajustando balance: %w", err)
+	}
 	return acc, nil
 }
 
-// Delete elimina una cuenta de ahorro.
+// Delete manda una cuenta de ahorro a la papelera. Su saldo deja de contar en el total
+// y sus transferencias se conservan (siguen apuntando a la cuenta por si se restaura).
 func (r *SavingsRepository) Delete(ctx context.Context, id, userID string) error {
 	result, err := r.pool.Exec(ctx,
-		`DELETE FROM savings_accounts WHERE id = $1 AND user_id = $2`,
+		`UPDATE savings_accounts SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
 		id, userID,
 	)
 	if err != nil {
@@ -126,7 +183,7 @@ func (r *SavingsRepository) Delete(ctx context.Context, id, userID string) error
 func (r *SavingsRepository) GetTotalByUser(ctx context.Context, userID string) (float64, error) {
 	var total float64
 	err := r.pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(balance), 0) FROM savings_accounts WHERE user_id = $1`,
+		`SELECT COALESCE(SUM(balance), 0) FROM savings_accounts WHERE user_id = $1 AND deleted_at IS NULL`,
 		userID,
 	).Scan(&total)
 	if err != nil {
@@ -134,3 +191,181 @@ func (r *SavingsRepository) GetTotalByUser(ctx context.Context, userID string) (
 	}
 	return total, nil
 }
+
+// GetMovements devuelve los movimientos de una cuenta, del más reciente al más antiguo
+// según su fecha, con paginación. El saldo de cada movimiento se recalcula en ese orden,
+// así una transferencia cargada con fecha pasada queda en su lugar del historial.
+func (r *SavingsRepository) GetMovements(ctx context.Context, filter models.SavingsMovementFilter) ([]models.SavingsMovement, int, error) {
+	baseQuery := `
+		FROM (
+			SELECT id, account_id, type, amount, note, transaction_id, occurred_on, created_at,
+				SUM(amount) OVER (ORDER BY occurred_on, created_at, id) AS balance_after
+			FROM savings_movements
+			WHERE account_id = $1 AND user_id = $2
+		) m
+		WHERE TRUE`
+
+	args := []interface{}{filter.AccountID, filter.UserID}
+	argIndex := 3
+
+	if filter.DateFrom != "" {
+		baseQuery += fmt.Sprintf(" AND occurred_on >= $%d::date", argIndex)
+		args = append(args, filter.DateFrom)
+		argIndex++
+	}
+
+	if filter.DateTo != "" {
+		baseQuery += fmt.Sprintf(" AND occurred_on <= $%d::date", argIndex)
+		args = append(args, filter.DateTo)
+		argIndex++
+	}
+
+	var total int
+	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) "+baseQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error contando movimientos: %w", err)
+	}
+
+	selectQuery := `SELECT id, account_id, type, amount, balance_after, note, transaction_id, occurred_on, created_at ` +
+		baseQuery + " ORDER BY occurred_on DESC, created_at DESC, id DESC"
+
+	offset := (filter.Page - 1) * filter.Limit
+	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, offset)
+
+	rows, err := r.pool.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error consultando movimientos: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []models.SavingsMovement
+	for rows.Next() {
+		var m models.SavingsMovement
+		err := rows.Scan(&m.ID, &m.AccountID, &m.Type, &m.Amount, &m.BalanceAfter, &m.Note, &m.TransactionID, &m.Date, &m.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error leyendo movimiento: %w", err)
+		}
+		m.DateStr = m.Date.Format("2006-01-02")
+		movements = append(movements, m)
+	}
+
+	return movements, total, nil
+}
+
+// GetBalanceHistory devuelve el saldo al cierre de cada periodo que tuvo movimientos,
+// agrupando por la fecha de cada movimiento.
+// granularity debe ser "day", "week" o "month" (se valida en el service).
+func (r *SavingsRepository) GetBalanceHistory(ctx context.Context, accountID, userID, granularity string) ([]models.BalancePoint, error) {
+	// El saldo es la suma acumulada en orden de fecha (el saldo inicial también es un
+	// movimiento); DISTINCT ON se queda con el último movimiento de cada periodo
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT ON (period) period, balance
+		 FROM (
+			SELECT date_trunc($3, occurred_on)::date AS period,
+				SUM(amount) OVER (ORDER BY occurred_on, created_at, id) AS balance,
+				occurred_on, created_at, id
+			FROM savings_movements
+			WHERE account_id = $1 AND user_id = $2
+		 ) m
+		 ORDER BY period, occurred_on DESC, created_at DESC, id DESC`,
+		accountID, userID, granularity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando historial de saldo: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.BalancePoint
+	for rows.Next() {
+		var p models.BalancePoint
+		var period time.Time
+		if err := rows.Scan(&period, &p.Balance); err != nil {
+			return nil, fmt.Errorf("error leyendo historial de saldo: %w", err)
+		}
+		p.Period = period.Format("2006-01-02")
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// scanSavingsAccount lee una fila con las columnas de savingsColumns.
+func scanSavingsAccount(row pgx.Row) (*models.SavingsAccount, error) {
+	acc := &models.SavingsAccount{}
+	err := row.Scan(&acc.ID, &acc.UserID, &acc.Name, &acc.Balance, &acc.Color, &acc.Icon, &acc.Notes, &acc.CreatedAt, &acc.UpdatedAt,
+		&acc.TargetAmount, &acc.TargetDate)
+	if err != nil {
+		return nil, err
+	}
+	acc.FormatTargetDate()
+	return acc, nil
+}
+
+// recordSavingsDelta suma (o resta, si delta es negativo) al saldo de una cuenta de ahorro
+// y deja el movimiento en el historial, dentro de una transacción ya abierta.
+// date es el día del movimiento ("2006-01-02"; "" = hoy).
+// Nunca deja el saldo en negativo: en ese caso devuelve ErrInsufficientFunds.
+func recordSavingsDelta(ctx context.Context, tx pgx.Tx, accountID, userID string, delta float64, movementType, note, transactionID, date string) error {
+	var balance float64
+	err := tx.QueryRow(ctx,
+		`UPDATE savings_accounts
+		 SET balance = balance + $3, updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND balance + $3 >= 0
+		 RETURNING balance`,
+		accountID, userID, delta,
+	).Scan(&balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if delta < 0 {
+			return ErrInsufficientFunds
+		}
+		return fmt.Errorf("cuenta de ahorro no encontrada")
+	}
+	if err != nil {
+		return fmt.Errorf("error actualizando saldo: %w", err)
+	}
+
+	return insertSavingsMovement(ctx, tx, accountID, userID, movementType, delta, balance, note, transactionID, date)
+}
+
+// insertSavingsMovement agrega una fila al historial de movimientos.
+// date es el día del movimiento ("2006-01-02"; "" = hoy).
+func insertSavingsMovement(ctx context.Context, tx pgx.Tx, accountID, userID, movementType string, amount, balanceAfter float64, note, transactionID, date string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO savings_movements (user_id, account_id, type, amount, balance_after, note, transaction_id, occurred_on)
+		 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid, COALESCE(NULLIF($8, '')::date, CURRENT_DATE))`,
+		userID, accountID, movementType, amount, balanceAfter, note, transactionID, date,
+	)
+	if err != nil {
+		return fmt.Errorf("error registrando movimiento de ahorro: %w", err)
+	}
+	return nil
+}
+
+// GetContributions devuelve, por cuenta, la suma neta de movimientos con fecha desde "since"
+// (sin contar el saldo inicial) y la fecha del primer movimiento.
+func (r *SavingsRepository) GetContributions(ctx context.Context, userID string, since time.Time) (map[string]models.SavingsContribution, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT account_id,
+			COALESCE(SUM(amount) FILTER (WHERE type <> 'initial' AND occurred_on >= $2::date), 0),
+			MIN(occurred_on)
+		 FROM savings_movements
+		 WHERE user_id = $1
+		 GROUP BY account_id`,
+		userID, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando aportes de ahorro: %w", err)
+	}
+	defer rows.Close()
+
+	contributions := make(map[string]models.SavingsContribution)
+	for rows.Next() {
+		var accountID string
+		var c models.SavingsContribution
+		if err := rows.Scan(&accountID, &c.Net, &c.FirstMovement); err != nil {
+			return nil, fmt.Errorf("error leyendo aportes de ahorro: %w", err)
+		}
+		contributions[accountID] = c
+	}
+	return contributions, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// transactionSplitsColumn agrega las divisiones de la transacción t como JSON (NULL si no está dividida).
+// Se usa dentro de transactionSelect.
+const transactionSplitsColumn = `(SELECT json_agg(json_build_object(
+		            'id', s.id, 'category_id', s.category_id, 'category_name', sc.name,
+		            'category_color', sc.color, 'amount', s.amount, 'note', s.note) ORDER BY s.position)
+		          FROM transaction_splits s JOIN categories sc ON sc.id = s.category_id
+		          WHERE s.transaction_id = t.id)`
+
+// setTransactionSplits reemplaza las divisiones de una transacción dentro de una transacción
+// de base de datos ya abierta. Cada categoría debe ser del usuario y del mismo tipo que la
+// transacción. Que los montos sumen el total lo valida el service.
+func setTransactionSplits(ctx context.Context, tx pgx.Tx, userID string, t *models.Transaction, splits []models.SplitRequest) ([]models.TransactionSplit, error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM transaction_splits WHERE transaction_id = $1`, t.ID); err != nil {
+		return nil, fmt.Errorf("error quitando divisiones: %w", err)
+	}
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	for i, split := range splits {
+		result, err := tx.Exec(ctx,
+			`INSERT INTO transaction_splits (transaction_id, category_id, amount, note, position)
+			 SELECT $1, $2, $3, $4, $5
+			 WHERE EXISTS (SELECT 1 FROM categories
+			               WHERE id = $2 AND user_id = $6 AND type = $7 AND deleted_at IS NULL)`,
+			t.ID, split.CategoryID, split.Amount, split.Note, i, userID, t.Type,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error guardando división %d: %w", i+1, err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, fmt.Errorf("la categoría de la división %d no existe o no es de tipo %s", i+1, t.Type)
+		}
+	}
+
+	return getTransactionSplits(ctx, tx, t.ID)
+}
+
+// getTransactionSplits devuelve las divisiones de una transacción en su orden original.
+func getTransactionSplits(ctx context.Context, tx pgx.Tx, transactionID string) ([]models.TransactionSplit, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT s.id, s.category_id, c.name, c.color, s.amount, s.note
+		 FROM transaction_splits s
+		 JOIN categories c ON c.id = s.category_id
+		 WHERE s.transaction_id = $1
+		 ORDER BY s.position`,
+		transactionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando divisiones: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []models.TransactionSplit
+	for rows.Next() {
+		var s models.TransactionSplit
+		if err := rows.Scan(&s.ID, &s.CategoryID, &s.CategoryName, &s.CategoryColor, &s.Amount, &s.Note); err != nil {
+			return nil, fmt.Errorf("error leyendo división: %w", err)
+		}
+		splits = append(splits, s)
+	}
+	return splits, rows.Err()
+}
@@ -0,0 +1,162 @@
+// Repository de etiquetas — operaciones SQL sobre tags y transaction_tags.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TagRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTagRepository(pool *pgxpool.Pool) *TagRepository {
+	return &TagRepository{pool: pool}
+}
+
+// GetAllByUser devuelve las etiquetas del usuario con cuántas transacciones tiene cada una.
+func (r *TagRepository) GetAllByUser(ctx context.Context, userID string) ([]models.Tag, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT tg.id, tg.user_id, tg.name, tg.color,
+		        (SELECT COUNT(*) FROM transaction_tags tt JOIN transactions t ON t.id = tt.transaction_id
+		         WHERE tt.tag_id = tg.id AND t.deleted_at IS NULL),
+		        tg.created_at
+		 FROM tags tg
+		 WHERE tg.user_id = $1
+		 ORDER BY tg.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando etiquetas: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.TransactionCount, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error leyendo etiqueta: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// Create inserta una etiqueta. name ya viene normalizado.
+func (r *TagRepository) Create(ctx context.Context, userID, name, color string) (*models.Tag, error) {
+	tag := &models.Tag{}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO tags (user_id, name, color)
+		 VALUES ($1, $2, COALESCE(NULLIF($3, ''), '#6b7280'))
+		 RETURNING id, user_id, name, color, created_at`,
+		userID, name, color,
+	).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.CreatedAt)
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una etiqueta llamada '%s'", name)
+		}
+		return nil, fmt.Errorf("error creando etiqueta: %w", err)
+	}
+	return tag, nil
+}
+
+// Update renombra una etiqueta o cambia su color. name ya viene normalizado.
+func (r *TagRepository) Update(ctx context.Context, id, userID, name, color string) (*models.Tag, error) {
+	tag := &models.Tag{}
+	err := r.pool.QueryRow(ctx,
+		`UPDATE tags SET name = COALESCE(NULLIF($1, ''), name), color = COALESCE(NULLIF($2, ''), color)
+		 WHERE id = $3 AND user_id = $4
+		 RETURNING id, user_id, name, color,
+		           (SELECT COUNT(*) FROM transaction_tags tt JOIN transactions t ON t.id = tt.transaction_id
+		            WHERE tt.tag_id = tags.id AND t.deleted_at IS NULL), created_at`,
+		name, color, id, userID,
+	).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.TransactionCount, &tag.CreatedAt)
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una etiqueta llamada '%s'", name)
+		}
+		return nil, fmt.Errorf("etiqueta no encontrada: %w", err)
+	}
+	return tag, nil
+}
+
+// Delete elimina una etiqueta (las transacciones se conservan, solo pierden la etiqueta).
+func (r *TagRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error eliminando etiqueta: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("etiqueta no encontrada o no tienes permiso")
+	}
+	return nil
+}
+
+// hasAllTagsCondition arma la condición SQL "la transacción t tiene todas las etiquetas
+// del parámetro $argIndex" (un arreglo de nombres normalizados y sin repetidos).
+func hasAllTagsCondition(argIndex int) string {
+	return fmt.Sprintf(`(SELECT COUNT(*) FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.transaction_id = t.id AND tg.name = ANY($%d)) = cardinality($%d::text[])`, argIndex, argIndex)
+}
+
+// setTransactionTags reemplaza las etiquetas de una transacción dentro de una transacción
+// de base de datos ya abierta. Las etiquetas que no existen se crean. Devuelve los nombres
+// guardados en orden alfabético.
+func setTransactionTags(ctx context.Context, tx pgx.Tx, userID, transactionID string, names []string) ([]string, error) {
+	names = models.NormalizeTagNames(names)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM transaction_tags WHERE transaction_id = $1`, transactionID); err != nil {
+		return nil, fmt.Errorf("error quitando etiquetas: %w", err)
+	}
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	for _, name := range names {
+		if len(name) > 50 {
+			return nil, fmt.Errorf("la etiqueta '%s' supera 50 caracteres", strings.TrimSpace(name))
+		}
+	}
+
+	_, err := tx.Exec(ctx,
+		`INSERT INTO tags (user_id, name)
+		 SELECT $1, unnest($2::text[])
+		 ON CONFLICT (user_id, name) DO NOTHING`,
+		userID, names,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creando etiquetas: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO transaction_tags (transaction_id, tag_id)
+		 SELECT $1, id FROM tags WHERE user_id = $2 AND name = ANY($3)`,
+		transactionID, userID, names,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error asignando etiquetas: %w", err)
+	}
+
+	return getTransactionTags(ctx, tx, transactionID)
+}
+
+// getTransactionTags devuelve los nombres de las etiquetas de una transacción.
+func getTransactionTags(ctx context.Context, tx pgx.Tx, transactionID string) ([]string, error) {
+	var names []string
+	err := tx.QueryRow(ctx,
+		`SELECT COALESCE(array_agg(tg.name ORDER BY tg.name), '{}')
+		 FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		 WHERE tt.transaction_id = $1`,
+		transactionID,
+	).Scan(&names)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando etiquetas: %w", err)
+	}
+	return names, nil
+}
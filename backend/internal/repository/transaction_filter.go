@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+)
+
+// transactionWhere es el WHERE de una consulta de transacciones (alias t) armado a partir
+// de un TransactionFilter. Los valores siempre van como parámetros ($1, $2...), nunca
+// concatenados al SQL, y las condiciones comparan columnas sin transformarlas para que
+// PostgreSQL pueda usar los índices (user_id + date, category_id, amount, search_vector).
+type transactionWhere struct {
+	sql         string
+	args        []interface{}
+	searchIndex int // Parámetro con el texto de búsqueda (0 = sin búsqueda)
+}
+
+func buildTransactionWhere(userID string, filter models.TransactionFilter) *transactionWhere {
+	// Las transacciones en la papelera nunca se listan, exportan ni suman
+	w := &transactionWhere{sql: `
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL`, args: []interface{}{userID}}
+
+	if filter.Type != "" {
+		w.add("t.type = $%d", filter.Type)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		// Una transacción dividida aparece en cada una de las categorías de sus divisiones
+		w.add(`(t.category_id = ANY($%[1]d::uuid[])
+			OR EXISTS (SELECT 1 FROM transaction_splits s
+			           WHERE s.transaction_id = t.id AND s.category_id = ANY($%[1]d::uuid[])))`, filter.CategoryIDs)
+	}
+	if len(filter.ExcludeCategoryIDs) > 0 {
+		// Se excluye si la transacción o alguna de sus divisiones es de esas categorías.
+		// Las transferencias no tienen categoría y nunca se excluyen por esto.
+		w.add(`(t.category_id IS NULL OR t.category_id <> ALL($%[1]d::uuid[]))
+			AND NOT EXISTS (SELECT 1 FROM transaction_splits s
+			                WHERE s.transaction_id = t.id AND s.category_id = ANY($%[1]d::uuid[]))`, filter.ExcludeCategoryIDs)
+	}
+	if filter.MinAmount != nil {
+		w.add("t.amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		w.add("t.amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.Currency != "" {
+		w.add("t.currency = $%d", filter.Currency)
+	}
+	if filter.DateFrom != "" {
+		w.add("t.date >= $%d", filter.DateFrom)
+	}
+	if filter.DateTo != "" {
+		w.add("t.date <= $%d", filter.DateTo)
+	}
+	if len(filter.Tags) > 0 {
+		w.sql += " AND " + hasAllTagsCondition(w.addArg(filter.Tags))
+	}
+	if filter.Query != "" {
+		w.searchIndex = w.addArg(filter.Query)
+		w.sql += " AND " + searchCondition(w.searchIndex)
+	}
+	return w
+}
+
+// transactionScope arma la condición "la transacción de column cumple scope" para consultas
+// que no listan transacciones sino que agregan (reportes). Devuelve "" si scope es nil.
+// Con lineCategory (la columna de categoría de transaction_lines, ej: "t.category_id"),
+// el filtro de categorías se aplica a cada línea y no a la transacción: de una transacción
+// dividida solo suman las divisiones de esas categorías.
+// Los argumentos devueltos empiezan con $1 = userID: quien llama agrega los suyos después.
+func transactionScope(userID, column, lineCategory string, scope *models.TransactionFilter) (string, []interface{}) {
+	if scope == nil {
+		return "", []interface{}{userID}
+	}
+	filter := *scope
+	if lineCategory != "" {
+		filter.CategoryIDs = nil
+		filter.ExcludeCategoryIDs = nil
+	}
+	where := buildTransactionWhere(userID, filter)
+	where.sql = fmt.Sprintf(" AND %s IN (SELECT t.id FROM transactions t%s)", column, where.sql)
+
+	if lineCategory != "" && len(scope.CategoryIDs) > 0 {
+		where.add(lineCategory+" = ANY($%d::uuid[])", scope.CategoryIDs)
+	}
+	if lineCategory != "" && len(scope.ExcludeCategoryIDs) > 0 {
+		// Las líneas sin categoría (transferencias) nunca se excluyen por esto
+		where.add(fmt.Sprintf("(%[1]s IS NULL OR %[1]s <> ALL($%%d::uuid[]))", lineCategory), scope.ExcludeCategoryIDs)
+	}
+	return where.sql, where.args
+}
+
+// add agrega una condición. format recibe el número del parámetro del valor ($%d o $%[1]d).
+func (w *transactionWhere) add(format string, value interface{}) {
+	w.sql += " AND " + fmt.Sprintf(format, w.addArg(value))
+}
+
+// addArg agrega un parámetro y devuelve su número.
+func (w *transactionWhere) addArg(value interface{}) int {
+	w.args = append(w.args, value)
+	return len(w.args)
+}
+
+// transactionSorts son los órdenes permitidos. El parámetro sort solo elige una entrada
+// de este mapa: nunca llega al SQL. %[1]s es la dirección (ASC o DESC).
+var transactionSorts = map[string]string{
+	"date":       "t.date %[1]s, t.created_at %[1]s",
+	"amount":     "t.amount %[1]s, t.date DESC",
+	"category":   "c.name %[1]s NULLS LAST, t.date DESC",
+	"created_at": "t.created_at %[1]s",
+}
+
+// transactionOrderBy devuelve el ORDER BY del filtro. Por defecto, las más recientes
+// primero; al buscar sin un orden explícito, las más relevantes primero. t.id desempata
+// para que la paginación sea estable; con orden por fecha, (date, created_at, id) es
+// además la clave de la paginación por cursor.
+func transactionOrderBy(filter models.TransactionFilter, searching bool) string {
+	if filter.Sort == "" && searching {
+		return " ORDER BY search_rank DESC, t.date DESC, t.created_at DESC, t.id"
+	}
+
+	sort, ok := transactionSorts[filter.Sort]
+	if !ok {
+		sort = transactionSorts["date"]
+	}
+	direction := "DESC"
+	if filter.Order == "asc" || (filter.Order == "" && filter.Sort == "category") {
+		direction = "ASC"
+	}
+	return " ORDER BY " + fmt.Sprintf(sort, direction) + ", t.id " + direction
+}
+
+// keysetCondition arma la condición "después del cursor" en el orden por fecha, con los
+// parámetros $argIndex (date), $argIndex+1 (created_at) y $argIndex+2 (id). La comparación
+// de filas usa el índice (user_id, date, created_at, id) en cualquiera de las dos direcciones.
+func keysetCondition(argIndex int, ascending bool) string {
+	op := "<"
+	if ascending {
+		op = ">"
+	}
+	return fmt.Sprintf("(t.date, t.created_at, t.id) %s ($%d::date, $%d::timestamptz, $%d::uuid)",
+		op, argIndex, argIndex+1, argIndex+2)
+}
+
+// searchCondition arma la condición de búsqueda de texto sobre la transacción t
+// con el texto del parámetro $argIndex.
+func searchCondition(argIndex int) string {
+	return fmt.Sprintf("t.search_vector @@ websearch_to_tsquery('es_unaccent', $%d)", argIndex)
+}
+
+// searchColumns son las columnas de relevancia y fragmento resaltado que se agregan
+// a transactionSelect al buscar. Se leen con scanTransaction(row, &rank, &snippet).
+func searchColumns(argIndex int) string {
+	return fmt.Sprintf(`,
+		ts_rank(t.search_vector, websearch_to_tsquery('es_unaccent', $%[1]d)) AS search_rank,
+		ts_headline('es_unaccent', t.description, websearch_to_tsquery('es_unaccent', $%[1]d),
+		            'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5') `, argIndex)
+}
@@ -2,14 +2,40 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"expense-tracker-backend/internal/models"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// transactionSelect son las columnas que se leen al listar transacciones.
+// Las transferencias no tienen categoría, por eso los JOIN son LEFT y se usa COALESCE.
+const transactionSelect = `SELECT t.id, t.user_id, COALESCE(t.category_id::text, ''),
+		COALESCE(c.name, ''), COALESCE(c.nickname, ''), COALESCE(c.color, ''), COALESCE(c.icon, ''),
+		t.amount, t.type, t.description, t.date, t.currency, t.recurring_id, t.external_id,
+		t.from_account_id, COALESCE(fa.name, ''), t.to_account_id, COALESCE(ta.name, ''), COALESCE(t.transfer_kind, ''),
+		COALESCE((SELECT array_agg(tg.name ORDER BY tg.name)
+		          FROM transaction_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		          WHERE tt.transaction_id = t.id), '{}'),
+		` + transactionSplitsColumn + `,
+		t.created_at, t.updated_at `
+
+// transactionJoins acompaña a transactionSelect (alias t, c, fa, ta).
+const transactionJoins = `
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN savings_accounts fa ON t.from_account_id = fa.id
+		LEFT JOIN savings_accounts ta ON t.to_account_id = ta.id`
+
+// transactionReturning es la cláusula RETURNING de INSERT/UPDATE (sin JOINs).
+const transactionReturning = `id, user_id, COALESCE(category_id::text, ''), amount, type, description, date, currency,
+		recurring_id, external_id, from_account_id, to_account_id, COALESCE(transfer_kind, ''), created_at, updated_at`
+
 type TransactionRepository struct {
 	pool *pgxpool.Pool
 }
@@ -18,153 +44,218 @@ func NewTransactionRepository(pool *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{pool: pool}
 }
 
-// GetFiltered devuelve transacciones filtradas con paginación.
-func (r *TransactionRepository) GetFiltered(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, int, error) {
-	baseQuery := `
-		FROM transactions t
-		JOIN categories c ON t.category_id = c.id
-		WHERE t.user_id = $1`
+// GetFiltered devuelve una página de transacciones filtradas y si hay más después de ella.
+// Pagina por offset (Page) o, con filter.Keyset, a partir del cursor filter.After.
+func (r *TransactionRepository) GetFiltered(ctx context.Context, filter models.TransactionFilter) ([]models.Transaction, bool, error) {
+	where := buildTransactionWhere(filter.UserID, filter)
+	searching := where.searchIndex > 0
 
-	args := []interface{}{filter.UserID}
-	argIndex := 2
+	if filter.Keyset && filter.After != nil {
+		argIndex := len(where.args) + 1
+		where.sql += " AND " + keysetCondition(argIndex, filter.Order == "asc")
+		where.args = append(where.args, filter.After.Date, filter.After.CreatedAt, filter.After.ID)
+	}
 
-	if filter.Type != "" {
-		baseQuery += fmt.Sprintf(" AND t.type = $%d", argIndex)
-		args = append(args, filter.Type)
-		argIndex++
+	query := transactionSelect
+	if searching {
+		query += searchColumns(where.searchIndex)
+	}
+	query += transactionJoins + where.sql + transactionOrderBy(filter, searching)
+
+	// Se pide una fila de más para saber si hay otra página sin contar
+	args := where.args
+	if filter.Keyset {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, filter.Limit+1)
+	} else {
+		offset := (filter.Page - 1) * filter.Limit
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, filter.Limit+1, offset)
 	}
 
-	if filter.CategoryID != "" {
-		baseQuery += fmt.Sprintf(" AND t.category_id = $%d", argIndex)
-		args = append(args, filter.CategoryID)
-		argIndex++
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("error consultando transacciones: %w", err)
 	}
+	defer rows.Close()
 
-	if filter.DateFrom != "" {
-		baseQuery += fmt.Sprintf(" AND t.date >= $%d", argIndex)
-		args = append(args, filter.DateFrom)
-		argIndex++
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		var err error
+		if searching {
+			var rank float64
+			var snippet string
+			t, err = scanTransaction(rows, &rank, &snippet)
+			t.Rank, t.Snippet = rank, snippet
+		} else {
+			t, err = scanTransaction(rows)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("error leyendo transacción: %w", err)
+		}
+		transactions = append(transactions, t)
 	}
 
-	if filter.DateTo != "" {
-		baseQuery += fmt.Sprintf(" AND t.date <= $%d", argIndex)
-		args = append(args, filter.DateTo)
-		argIndex++
+	hasMore := len(transactions) > filter.Limit
+	if hasMore {
+		transactions = transactions[:filter.Limit]
 	}
+	return transactions, hasMore, nil
+}
+
+// CountFiltered cuenta todas las transacciones que cumplen el filtro (sin paginar).
+func (r *TransactionRepository) CountFiltered(ctx context.Context, filter models.TransactionFilter) (int, error) {
+	where := buildTransactionWhere(filter.UserID, filter)
 
 	var total int
-	countQuery := "SELECT COUNT(*) " + baseQuery
-	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
+	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) "+transactionJoins+where.sql, where.args...).Scan(&total)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error contando transacciones: %w", err)
+		return 0, fmt.Errorf("error contando transacciones: %w", err)
 	}
+	return total, nil
+}
 
-	selectQuery := `SELECT t.id, t.user_id, t.category_id, c.name, c.nickname, c.color, c.icon,
-		t.amount, t.type, t.description, t.date, t.currency, t.created_at, t.updated_at ` +
-		baseQuery + " ORDER BY t.date DESC, t.created_at DESC"
-
-	offset := (filter.Page - 1) * filter.Limit
-	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, filter.Limit, offset)
+// GetAllForExport devuelve TODAS las transacciones del usuario (sin paginación) para CSV.
+func (r *TransactionRepository) GetAllForExport(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
+	where := buildTransactionWhere(userID, filter)
+	query := transactionSelect + transactionJoins + where.sql + transactionOrderBy(filter, false)
 
-	rows, err := r.pool.Query(ctx, selectQuery, args...)
+	rows, err := r.pool.Query(ctx, query, where.args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error consultando transacciones: %w", err)
+		return nil, fmt.Errorf("error consultando transacciones para export: %w", err)
 	}
 	defer rows.Close()
 
 	var transactions []models.Transaction
 	for rows.Next() {
-		var t models.Transaction
-		err := rows.Scan(
-			&t.ID, &t.UserID, &t.CategoryID, &t.CategoryName, &t.CategoryNickname, &t.CategoryColor, &t.CategoryIcon,
-			&t.Amount, &t.Type, &t.Description, &t.Date, &t.Currency,
-			&t.CreatedAt, &t.UpdatedAt,
-		)
+		t, err := scanTransaction(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("error leyendo transacción: %w", err)
+			return nil, fmt.Errorf("error leyendo transacción: %w", err)
 		}
-		t.FormatDate()
 		transactions = append(transactions, t)
 	}
 
-	return transactions, total, nil
+	return transactions, nil
 }
 
-// GetAllForExport devuelve TODAS las transacciones del usuario (sin paginación) para CSV.
-func (r *TransactionRepository) GetAllForExport(ctx context.Context, userID string, filter models.TransactionFilter) ([]models.Transaction, error) {
-	baseQuery := `
-		SELECT t.id, t.user_id, t.category_id, c.name, c.nickname, c.color, c.icon,
-			t.amount, t.type, t.description, t.date, t.currency, t.created_at, t.updated_at
-		FROM transactions t
-		JOIN categories c ON t.category_id = c.id
-		WHERE t.user_id = $1`
-
-	args := []interface{}{userID}
-	argIndex := 2
-
-	if filter.DateFrom != "" {
-		baseQuery += fmt.Sprintf(" AND t.date >= $%d", argIndex)
-		args = append(args, filter.DateFrom)
-		argIndex++
-	}
-
-	if filter.DateTo != "" {
-		baseQuery += fmt.Sprintf(" AND t.date <= $%d", argIndex)
-		args = append(args, filter.DateTo)
-		argIndex++
+// GetByID devuelve una transacción del usuario.
+func (r *TransactionRepository) GetByID(ctx context.Context, id, userID string) (*models.Transaction, error) {
+	t, err := scanTransaction(r.pool.QueryRow(ctx,
+		transactionSelect+transactionJoins+` WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL`,
+		id, userID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("transacción no encontrada: %w", err)
 	}
+	return &t, nil
+}
 
-	_ = argIndex
-
-	baseQuery += " ORDER BY t.date DESC"
-
-	rows, err := r.pool.Query(ctx, baseQuery, args...)
+// GetTrainingSamples devuelve los últimos ingresos y gastos del usuario (solo categoría,
+// descripción y monto) para entrenar el clasificador de categorías.
+func (r *TransactionRepository) GetTrainingSamples(ctx context.Context, userID string, limit int) ([]models.Transaction, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT category_id, description, amount
+		 FROM transactions
+		 WHERE user_id = $1 AND type IN ('income', 'expense') AND category_id IS NOT NULL AND deleted_at IS NULL
+		 ORDER BY date DESC, created_at DESC
+		 LIMIT $2`,
+		userID, limit,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("error consultando transacciones para export: %w", err)
+		return nil, fmt.Errorf("error consultando transacciones para entrenar: %w", err)
 	}
 	defer rows.Close()
 
-	var transactions []models.Transaction
+	var samples []models.Transaction
 	for rows.Next() {
 		var t models.Transaction
-		err := rows.Scan(
-			&t.ID, &t.UserID, &t.CategoryID, &t.CategoryName, &t.CategoryNickname, &t.CategoryColor, &t.CategoryIcon,
-			&t.Amount, &t.Type, &t.Description, &t.Date, &t.Currency,
-			&t.CreatedAt, &t.UpdatedAt,
-		)
-		if err != nil {
+		if err := rows.Scan(&t.CategoryID, &t.Description, &t.Amount); err != nil {
 			return nil, fmt.Errorf("error leyendo transacción: %w", err)
 		}
-		t.FormatDate()
-		transactions = append(transactions, t)
+		samples = append(samples, t)
 	}
-
-	return transactions, nil
+	return samples, nil
 }
 
-// Create inserta una nueva transacción.
+// Create inserta una nueva transacción junto con sus etiquetas y divisiones.
 func (r *TransactionRepository) Create(ctx context.Context, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
 	currency := req.Currency
 	if currency == "" {
 		currency = "COP"
 	}
 
-	t := &models.Transaction{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO transactions (user_id, category_id, amount, type, description, date, currency)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)
-		 RETURNING id, user_id, category_id, amount, type, description, date, currency, created_at, updated_at`,
-		userID, req.CategoryID, req.Amount, req.Type, req.Description, req.Date, currency,
-	).Scan(&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Type, &t.Description, &t.Date, &t.Currency, &t.CreatedAt, &t.UpdatedAt)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// FOR SHARE: la categoría no puede ir a la papelera mientras se crea la transacción
+	var exists int
+	err = tx.QueryRow(ctx,
+		`SELECT 1 FROM categories WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR SHARE`,
+		req.CategoryID, userID,
+	).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error consultando categoría: %w", err)
+	}
 
+	t, err := scanReturnedTransaction(tx.QueryRow(ctx,
+		`INSERT INTO transactions (user_id, category_id, amount, type, description, date, currency, recurring_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid)
+		 RETURNING `+transactionReturning,
+		userID, req.CategoryID, req.Amount, req.Type, req.Description, req.Date, currency, req.RecurringID,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("error creando transacción: %w", err)
 	}
-	t.FormatDate()
+
+	if t.Tags, err = setTransactionTags(ctx, tx, userID, t.ID, req.Tags); err != nil {
+		return nil, err
+	}
+	if t.Splits, err = setTransactionSplits(ctx, tx, userID, t, req.Splits); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
 	return t, nil
 }
 
+// GetExistingExternalIDs devuelve cuáles de los IDs externos ya tienen transacción del usuario.
+// Cuentan también las que están en la papelera: reimportar el extracto no debe revivirlas.
+// La excepción son las de un import deshecho, que se reemplazan al importar de nuevo.
+func (r *TransactionRepository) GetExistingExternalIDs(ctx context.Context, userID string, externalIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT external_id FROM transactions
+		 WHERE user_id = $1 AND external_id = ANY($2) AND NOT (`+undoneImportRow+`)`,
+		userID, externalIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando IDs externos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error leyendo ID externo: %w", err)
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
 // Update actualiza una transacción existente.
 func (r *TransactionRepository) Update(ctx context.Context, id, userID string, req models.UpdateTransactionRequest) (*models.Transaction, error) {
 	sets := []string{}
@@ -202,35 +293,60 @@ func (r *TransactionRepository) Update(ctx context.Context, id, userID string, r
 		argIndex++
 	}
 
-	if len(sets) == 0 {
+	if len(sets) == 0 && req.Tags == nil && req.Splits == nil {
 		return nil, fmt.Errorf("no se proporcionaron campos para actualizar")
 	}
 
 	sets = append(sets, "updated_at = NOW()")
 
 	query := fmt.Sprintf(
-		`UPDATE transactions SET %s WHERE id = $%d AND user_id = $%d
-		 RETURNING id, user_id, category_id, amount, type, description, date, currency, created_at, updated_at`,
+		`UPDATE transactions SET %s WHERE id = $%d AND user_id = $%d AND type <> 'transfer' AND deleted_at IS NULL
+		 RETURNING `+transactionReturning,
 		strings.Join(sets, ", "), argIndex, argIndex+1,
 	)
 	args = append(args, id, userID)
 
-	t := &models.Transaction{}
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Type, &t.Description,
-		&t.Date, &t.Currency, &t.CreatedAt, &t.UpdatedAt,
-	)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	t, err := scanReturnedTransaction(tx.QueryRow(ctx, query, args...))
 	if err != nil {
 		return nil, fmt.Errorf("error actualizando transacción: %w", err)
 	}
-	t.FormatDate()
+
+	if req.Tags != nil {
+		t.Tags, err = setTransactionTags(ctx, tx, userID, t.ID, req.Tags)
+	} else {
+		t.Tags, err = getTransactionTags(ctx, tx, t.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Splits != nil {
+		t.Splits, err = setTransactionSplits(ctx, tx, userID, t, req.Splits)
+	} else {
+		t.Splits, err = getTransactionSplits(ctx, tx, t.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
 	return t, nil
 }
 
-// Delete elimina una transacción del usuario.
+// Delete manda una transacción del usuario a la papelera (ver TrashRepository).
+// Las transferencias no se borran aquí: se revierten con TransferRepository.Delete.
 func (r *TransactionRepository) Delete(ctx context.Context, id, userID string) error {
 	result, err := r.pool.Exec(ctx,
-		`DELETE FROM transactions WHERE id = $1 AND user_id = $2`,
+		`UPDATE transactions SET deleted_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND type <> 'transfer' AND deleted_at IS NULL`,
 		id, userID,
 	)
 	if err != nil {
@@ -241,3 +357,96 @@ func (r *TransactionRepository) Delete(ctx context.Context, id, userID string) e
 	}
 	return nil
 }
+
+// updateGeneratedFrom aplica los cambios de una plantilla recurrente a las transacciones
+// que ya generó con fecha >= fromDate, dentro de una transacción ya abierta.
+// Devuelve cuántas filas se actualizaron.
+func updateGeneratedFrom(ctx context.Context, tx pgx.Tx, userID, recurringID, fromDate string, req models.UpdateTransactionRequest) (int64, error) {
+	sets := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.CategoryID != "" {
+		sets = append(sets, fmt.Sprintf("category_id = $%d", argIndex))
+		args = append(args, req.CategoryID)
+		argIndex++
+	}
+	if req.Amount > 0 {
+		sets = append(sets, fmt.Sprintf("amount = $%d", argIndex))
+		args = append(args, req.Amount)
+		argIndex++
+	}
+	if req.Description != "" {
+		sets = append(sets, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, req.Description)
+		argIndex++
+	}
+	if req.Currency != "" {
+		sets = append(sets, fmt.Sprintf("currency = $%d", argIndex))
+		args = append(args, req.Currency)
+		argIndex++
+	}
+
+	if len(sets) == 0 {
+		return 0, nil
+	}
+
+	sets = append(sets, "updated_at = NOW()")
+
+	query := fmt.Sprintf(
+		`UPDATE transactions SET %s
+		 WHERE user_id = $%d AND recurring_id = $%d AND date >= $%d AND deleted_at IS NULL`,
+		strings.Join(sets, ", "), argIndex, argIndex+1, argIndex+2,
+	)
+	// Las que el usuario dividió conservan su monto y categoría: cambiarlos rompería las divisiones
+	if req.Amount > 0 || req.CategoryID != "" {
+		query += ` AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = transactions.id)`
+	}
+	args = append(args, userID, recurringID, fromDate)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error propagando cambios de recurrente: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// IsUniqueViolation indica si el error viene de una restricción UNIQUE de PostgreSQL.
+// El worker de recurrentes lo usa para saber que una ocurrencia ya existía.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// scanTransaction lee una fila producida por transactionSelect. extra recibe las
+// columnas adicionales que siguen a las de transactionSelect (ej: searchColumns).
+func scanTransaction(row pgx.Row, extra ...interface{}) (models.Transaction, error) {
+	var t models.Transaction
+	dest := []interface{}{
+		&t.ID, &t.UserID, &t.CategoryID, &t.CategoryName, &t.CategoryNickname, &t.CategoryColor, &t.CategoryIcon,
+		&t.Amount, &t.Type, &t.Description, &t.Date, &t.Currency, &t.RecurringID, &t.ExternalID,
+		&t.FromAccountID, &t.FromAccountName, &t.ToAccountID, &t.ToAccountName, &t.TransferKind,
+		&t.Tags, &t.Splits, &t.CreatedAt, &t.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return t, err
+	}
+	t.FormatDate()
+	return t, nil
+}
+
+// scanReturnedTransaction lee una fila producida por "RETURNING " + transactionReturning.
+func scanReturnedTransaction(row pgx.Row) (*models.Transaction, error) {
+	t := &models.Transaction{}
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.CategoryID, &t.Amount, &t.Type, &t.Description, &t.Date, &t.Currency,
+		&t.RecurringID, &t.ExternalID, &t.FromAccountID, &t.ToAccountID, &t.TransferKind, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.Tags = []string{} // RETURNING no trae etiquetas: quien las necesite las llena después
+	t.FormatDate()
+	return t, nil
+}
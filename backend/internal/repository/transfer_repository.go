@@ -0,0 +1,123 @@
+// Repository de transferencias — mueve saldo entre cuentas de ahorro y registra
+// la transferencia en transactions, todo dentro de una sola transacción de PostgreSQL.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInsufficientFunds se devuelve cuando la cuenta de origen no tiene saldo suficiente.
+var ErrInsufficientFunds = errors.New("fondos insuficientes en la cuenta de origen")
+
+type TransferRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransferRepository(pool *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{pool: pool}
+}
+
+// Create debita un lado, acredita el otro y guarda la fila de transactions de forma atómica.
+// Si algo falla en el camino, no queda ningún cambio aplicado.
+func (r *TransferRepository) Create(ctx context.Context, userID string, req models.CreateTransferRequest) (*models.Transaction, error) {
+	currency := req.Currency
+	if currency == "" {
+		currency = "COP"
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transferencia: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	t, err := scanReturnedTransaction(tx.QueryRow(ctx,
+		`INSERT INTO transactions (user_id, amount, type, description, date, currency, from_account_id, to_account_id, transfer_kind)
+		 VALUES ($1, $2, 'transfer', $3, $4, $5, NULLIF($6, '')::uuid, NULLIF($7, '')::uuid, $8)
+		 RETURNING `+transactionReturning,
+		userID, req.Amount, req.Description, req.Date, currency, req.FromAccountID, req.ToAccountID, req.TransferKind(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error registrando transferencia: %w", err)
+	}
+
+	note := req.Description
+	if note == "" {
+		note = "Transferencia"
+	}
+	if req.FromAccountID != "" {
+		if err := recordSavingsDelta(ctx, tx, req.FromAccountID, userID, -req.Amount, "transfer_out", note, t.ID, req.Date); err != nil {
+			return nil, err
+		}
+	}
+	if req.ToAccountID != "" {
+		if err := recordSavingsDelta(ctx, tx, req.ToAccountID, userID, req.Amount, "transfer_in", note, t.ID, req.Date); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error confirmando transferencia: %w", err)
+	}
+	return t, nil
+}
+
+// Delete revierte una transferencia: devuelve el dinero a su origen y borra la fila.
+func (r *TransferRepository) Delete(ctx context.Context, id, userID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error iniciando reversión: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := reverseTransfer(ctx, tx, id, userID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error confirmando reversión: %w", err)
+	}
+	return nil
+}
+
+// reverseTransfer deshace una transferencia dentro de una transacción ya abierta.
+func reverseTransfer(ctx context.Context, tx pgx.Tx, id, userID string) error {
+	var amount float64
+	var fromID, toID *string
+	err := tx.QueryRow(ctx,
+		`SELECT amount, from_account_id, to_account_id
+		 FROM transactions
+		 WHERE id = $1 AND user_id = $2 AND type = 'transfer'
+		 FOR UPDATE`,
+		id, userID,
+	).Scan(&amount, &fromID, &toID)
+	if err != nil {
+		return fmt.Errorf("transferencia no encontrada: %w", err)
+	}
+
+	// El destino devuelve el dinero y el origen lo recupera.
+	// Si una de las cuentas ya fue eliminada (columna en NULL) ese lado se ignora.
+	// Si está en la papelera el saldo se corrige igual, para que quede bien al restaurarla.
+	if toID != nil {
+		if err := recordSavingsDelta(ctx, tx, *toID, userID, -amount, "transfer_reversal", "Reversión de transferencia", id, ""); err != nil {
+			return err
+		}
+	}
+	if fromID != nil {
+		if err := recordSavingsDelta(ctx, tx, *fromID, userID, amount, "transfer_reversal", "Reversión de transferencia", id, ""); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM transactions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error eliminando transferencia: %w", err)
+	}
+	return nil
+}
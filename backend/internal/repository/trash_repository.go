@@ -0,0 +1,248 @@
+// Repository de la papelera — lista, restaura y purga las filas con deleted_at
+// de transactions, categories, savings_accounts y budgets.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrTrashItemNotFound se devuelve al restaurar algo que no está en la papelera del usuario.
+var ErrTrashItemNotFound = errors.New("elemento no encontrado en la papelera")
+
+type TrashRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTrashRepository(pool *pgxpool.Pool) *TrashRepository {
+	return &TrashRepository{pool: pool}
+}
+
+// GetAll devuelve todo lo que el usuario tiene en la papelera, lo más reciente primero.
+func (r *TrashRepository) GetAll(ctx context.Context, userID string) ([]models.TrashItem, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT 'transaction', t.id, COALESCE(NULLIF(t.description, ''), c.name, ''), t.amount, t.deleted_at
+		 FROM transactions t
+		 LEFT JOIN categories c ON c.id = t.category_id
+		 WHERE t.user_id = $1 AND t.deleted_at IS NOT NULL
+		 UNION ALL
+		 SELECT 'category', id, name, NULL, deleted_at
+		 FROM categories
+		 WHERE user_id = $1 AND deleted_at IS NOT NULL
+		 UNION ALL
+		 SELECT 'savings_account', id, name, balance, deleted_at
+		 FROM savings_accounts
+		 WHERE user_id = $1 AND deleted_at IS NOT NULL
+		 UNION ALL
+		 SELECT 'budget', b.id,
+		        c.name || ' (' || CASE WHEN b.period_type = 'monthly'
+		                               THEN to_char(b.start_date, 'FMMM/YYYY')
+		                               ELSE b.start_date || ' – ' || b.end_date END || ')',
+		        b.amount_limit, b.deleted_at
+		 FROM budgets b
+		 JOIN categories c ON c.id = b.category_id
+		 WHERE b.user_id = $1 AND b.deleted_at IS NOT NULL
+		 ORDER BY 5 DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando papelera: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.TrashItem
+	for rows.Next() {
+		var item models.TrashItem
+		if err := rows.Scan(&item.Type, &item.ID, &item.Label, &item.Amount, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("error leyendo elemento de la papelera: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Restore saca un elemento de la papelera. Lo que necesita para volver a verse también
+// se restaura: la categoría de una transacción o de un presupuesto, y los presupuestos
+// que se borraron junto con una categoría.
+func (r *TrashRepository) Restore(ctx context.Context, userID, itemType, id string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error iniciando restauración: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var restored bool
+	switch itemType {
+	case models.TrashTransaction:
+		restored, err = restoreTransaction(ctx, tx, userID, id)
+	case models.TrashCategory:
+		restored, err = restoreCategories(ctx, tx, userID, []string{id})
+	case models.TrashSavingsAccount:
+		restored, err = restoreSavingsAccount(ctx, tx, userID, id)
+	case models.TrashBudget:
+		restored, err = restoreBudget(ctx, tx, userID, id)
+	default:
+		return fmt.Errorf("tipo de elemento desconocido: %s", itemType)
+	}
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return ErrTrashItemNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error confirmando restauración: %w", err)
+	}
+	return nil
+}
+
+// Purge elimina definitivamente lo que se borró antes de cutoff, de todos los usuarios.
+// Las categorías que todavía usa alguna transacción (aunque esté en la papelera) esperan
+// a que esa transacción se purgue. Devuelve cuántas filas se eliminaron.
+func (r *TrashRepository) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando purga: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// El orden importa: primero lo que referencia a categorías y cuentas
+	statements := []string{
+		`DELETE FROM transactions WHERE deleted_at < $1`,
+		`DELETE FROM budgets WHERE deleted_at < $1`,
+		`DELETE FROM categories c
+		 WHERE c.deleted_at < $1
+		   AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = c.id)
+		   AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.category_id = c.id)
+		   AND NOT EXISTS (SELECT 1 FROM recurring_transactions rt WHERE rt.category_id = c.id)`,
+		`DELETE FROM savings_accounts WHERE deleted_at < $1`,
+		`DELETE FROM import_batches b
+		 WHERE b.deleted_at < $1
+		   AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.import_batch_id = b.id)`,
+	}
+
+	var purged int64
+	for _, statement := range statements {
+		result, err := tx.Exec(ctx, statement, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("error purgando papelera: %w", err)
+		}
+		purged += result.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error confirmando purga: %w", err)
+	}
+	return purged, nil
+}
+
+// restoreTransaction restaura una transacción y las categorías que usa (la suya y las de sus divisiones).
+func restoreTransaction(ctx context.Context, tx pgx.Tx, userID, id string) (bool, error) {
+	result, err := tx.Exec(ctx,
+		`UPDATE transactions SET deleted_at = NULL, updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error restaurando transacción: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT category_id FROM transactions WHERE id = $1 AND category_id IS NOT NULL
+		 UNION
+		 SELECT category_id FROM transaction_splits WHERE transaction_id = $1`,
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error consultando categorías de la transacción: %w", err)
+	}
+	var categoryIDs []string
+	for rows.Next() {
+		var categoryID string
+		if err := rows.Scan(&categoryID); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("error leyendo categoría de la transacción: %w", err)
+		}
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	rows.Close()
+
+	if _, err := restoreCategories(ctx, tx, userID, categoryIDs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreCategories restaura las categorías indicadas que estén en la papelera, junto con
+// los presupuestos que se borraron con ellas (misma marca deleted_at).
+// Devuelve si restauró alguna.
+func restoreCategories(ctx context.Context, tx pgx.Tx, userID string, ids []string) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE budgets b SET deleted_at = NULL, updated_at = NOW()
+		 FROM categories c
+		 WHERE b.category_id = c.id AND b.deleted_at = c.deleted_at
+		   AND c.id = ANY($1::uuid[]) AND c.user_id = $2`,
+		ids, userID,
+	); err != nil {
+		return false, fmt.Errorf("error restaurando presupuestos de la categoría: %w", err)
+	}
+
+	result, err := tx.Exec(ctx,
+		`UPDATE categories SET deleted_at = NULL
+		 WHERE id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NOT NULL`,
+		ids, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error restaurando categoría: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func restoreSavingsAccount(ctx context.Context, tx pgx.Tx, userID, id string) (bool, error) {
+	result, err := tx.Exec(ctx,
+		`UPDATE savings_accounts SET deleted_at = NULL, updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error restaurando cuenta de ahorro: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// restoreBudget restaura un presupuesto y, si su categoría también está en la papelera, la categoría.
+func restoreBudget(ctx context.Context, tx pgx.Tx, userID, id string) (bool, error) {
+	var categoryID string
+	err := tx.QueryRow(ctx,
+		`UPDATE budgets SET deleted_at = NULL, updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+		 RETURNING category_id`,
+		id, userID,
+	).Scan(&categoryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error restaurando presupuesto: %w", err)
+	}
+
+	if _, err := restoreCategories(ctx, tx, userID, []string{categoryID}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -6,6 +6,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 
@@ -30,9 +31,9 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash, name s
 	err := r.pool.QueryRow(ctx,
 		`INSERT INTO users (email, password_hash, name)
 		 VALUES ($1, $2, $3)
-		 RETURNING id, email, password_hash, name, include_savings_in_total, created_at, updated_at`,
+		 RETURNING id, email, password_hash, name, include_savings_in_total, auto_copy_budgets, created_at, updated_at`,
 		email, passwordHash, name,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.AutoCopyBudgets, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("error creando usuario: %w", err)
@@ -45,10 +46,10 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash, name s
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, email, password_hash, name, COALESCE(include_savings_in_total, true), created_at, updated_at
+		`SELECT id, email, password_hash, name, COALESCE(include_savings_in_total, true), auto_copy_budgets, created_at, updated_at
 		 FROM users WHERE email = $1`,
 		email,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.AutoCopyBudgets, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("usuario no encontrado: %w", err)
@@ -61,10 +62,10 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	user := &models.User{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, email, password_hash, name, COALESCE(include_savings_in_total, true), created_at, updated_at
+		`SELECT id, email, password_hash, name, COALESCE(include_savings_in_total, true), auto_copy_budgets, created_at, updated_at
 		 FROM users WHERE id = $1`,
 		id,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.IncludeSavingsInTotal, &user.AutoCopyBudgets, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("usuario no encontrado: %w", err)
@@ -97,6 +98,41 @@ func (r *UserRepository) UpdateIncludeSavingsInTotal(ctx context.Context, userID
 	return nil
 }
 
+// UpdateAutoCopyBudgets activa o desactiva la copia automática de presupuestos.
+// Al activarla se marcan como ya copiados la semana, el mes, el trimestre y el año de now:
+// la primera copia de cada tipo es la del periodo siguiente.
+func (r *UserRepository) UpdateAutoCopyBudgets(ctx context.Context, userID string, value bool, now time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error actualizando preferencia: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE users SET auto_copy_budgets = $1, updated_at = NOW() WHERE id = $2`,
+		value, userID,
+	); err != nil {
+		return fmt.Errorf("error actualizando preferencia: %w", err)
+	}
+	if value {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO budget_auto_copies (user_id, period_type, start_date)
+			 SELECT $1, p.period_type, date_trunc(p.unit, $2::date)::date
+			 FROM (VALUES ('weekly', 'week'), ('monthly', 'month'), ('quarterly', 'quarter'), ('yearly', 'year'))
+			      AS p(period_type, unit)
+			 ON CONFLICT DO NOTHING`,
+			userID, now.Format("2006-01-02"),
+		); err != nil {
+			return fmt.Errorf("error actualizando preferencia: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error actualizando preferencia: %w", err)
+	}
+	return nil
+}
+
 // Delete elimina un usuario por ID. Las tablas con FK a users (ON DELETE CASCADE) se limpian solas.
 func (r *UserRepository) Delete(ctx context.Context, userID string) error {
 	_, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
@@ -0,0 +1,111 @@
+// Repository de vistas guardadas — operaciones SQL sobre saved_views.
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"expense-tracker-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const viewColumns = `id, user_id, name, filters, created_at, updated_at`
+
+type ViewRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewViewRepository(pool *pgxpool.Pool) *ViewRepository {
+	return &ViewRepository{pool: pool}
+}
+
+// GetAllByUser devuelve las vistas del usuario en orden alfabético.
+func (r *ViewRepository) GetAllByUser(ctx context.Context, userID string) ([]models.SavedView, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+viewColumns+` FROM saved_views WHERE user_id = $1 ORDER BY name`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando vistas: %w", err)
+	}
+	defer rows.Close()
+
+	var views []models.SavedView
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo vista: %w", err)
+		}
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+// GetByID devuelve una vista del usuario.
+func (r *ViewRepository) GetByID(ctx context.Context, id, userID string) (*models.SavedView, error) {
+	v, err := scanView(r.pool.QueryRow(ctx,
+		`SELECT `+viewColumns+` FROM saved_views WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("vista no encontrada: %w", err)
+	}
+	return &v, nil
+}
+
+// Create guarda una vista.
+func (r *ViewRepository) Create(ctx context.Context, userID string, req models.CreateViewRequest) (*models.SavedView, error) {
+	v, err := scanView(r.pool.QueryRow(ctx,
+		`INSERT INTO saved_views (user_id, name, filters)
+		 VALUES ($1, $2, $3)
+		 RETURNING `+viewColumns,
+		userID, req.Name, req.Filters,
+	))
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una vista llamada '%s'", req.Name)
+		}
+		return nil, fmt.Errorf("error creando vista: %w", err)
+	}
+	return &v, nil
+}
+
+// Update renombra una vista y/o reemplaza sus filtros.
+func (r *ViewRepository) Update(ctx context.Context, id, userID string, req models.UpdateViewRequest) (*models.SavedView, error) {
+	v, err := scanView(r.pool.QueryRow(ctx,
+		`UPDATE saved_views
+		 SET name = COALESCE(NULLIF($1, ''), name),
+		     filters = COALESCE($2, filters),
+		     updated_at = NOW()
+		 WHERE id = $3 AND user_id = $4
+		 RETURNING `+viewColumns,
+		req.Name, req.Filters, id, userID,
+	))
+	if err != nil {
+		if IsUniqueViolation(err) {
+			return nil, fmt.Errorf("ya existe una vista llamada '%s'", req.Name)
+		}
+		return nil, fmt.Errorf("vista no encontrada: %w", err)
+	}
+	return &v, nil
+}
+
+// Delete elimina una vista del usuario.
+func (r *ViewRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM saved_views WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error eliminando vista: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("vista no encontrada o no tienes permiso")
+	}
+	return nil
+}
+
+func scanView(row pgx.Row) (models.SavedView, error) {
+	var v models.SavedView
+	err := row.Scan(&v.ID, &v.UserID, &v.Name, &v.Filters, &v.CreatedAt, &v.UpdatedAt)
+	return v, err
+}
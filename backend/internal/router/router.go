@@ -5,6 +5,7 @@ package router
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"expense-tracker-backend/internal/email"
 	"expense-tracker-backend/internal/handlers"
@@ -16,10 +17,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Services son los services que además usan los workers en segundo plano.
+// Setup los crea una sola vez y los devuelve para que los workers compartan las mismas
+// instancias que los handlers (y el mismo clasificador de sugerencias).
+type Services struct {
+	Recurring *services.RecurringService
+	Trash     *services.TrashService
+	Budget    *services.BudgetService
+}
+
 // Setup crea y configura el router de Gin con todas las rutas.
 // corsOrigin permite agregar dominios adicionales para CORS (producción).
 // resendAPIKey es la clave de Resend para enviar emails (puede estar vacía en dev).
-func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey string) *gin.Engine {
+// trashRetention es cuánto se conserva lo borrado en la papelera (también lo usa la purga).
+func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey string, trashRetention time.Duration) (*gin.Engine, *Services) {
 	router := gin.New()
 
 	// Middlewares globales
@@ -32,9 +43,18 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 	categoryRepo := repository.NewCategoryRepository(pool)
 	transactionRepo := repository.NewTransactionRepository(pool)
 	budgetRepo := repository.NewBudgetRepository(pool)
+	budgetTemplateRepo := repository.NewBudgetTemplateRepository(pool)
 	reportRepo := repository.NewReportRepository(pool)
 	savingsRepo := repository.NewSavingsRepository(pool)
 	passwordResetRepo := repository.NewPasswordResetRepository(pool)
+	recurringRepo := repository.NewRecurringRepository(pool)
+	transferRepo := repository.NewTransferRepository(pool)
+	importBatchRepo := repository.NewImportBatchRepository(pool)
+	duplicateRepo := repository.NewDuplicateRepository(pool)
+	ruleRepo := repository.NewRuleRepository(pool)
+	tagRepo := repository.NewTagRepository(pool)
+	viewRepo := repository.NewViewRepository(pool)
+	trashRepo := repository.NewTrashRepository(pool)
 
 	// --- Crear servicio de email (Resend) ---
 	var emailService *email.ResendService
@@ -47,20 +67,36 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 
 	// --- Crear services ---
 	authService := services.NewAuthService(userRepo, categoryRepo, passwordResetRepo, emailService, jwtSecret)
-	categoryService := services.NewCategoryService(categoryRepo)
-	transactionService := services.NewTransactionService(transactionRepo)
-	budgetService := services.NewBudgetService(budgetRepo)
+	suggestionService := services.NewSuggestionService(transactionRepo, categoryRepo)
+	categoryService := services.NewCategoryService(categoryRepo, suggestionService)
+	tagService := services.NewTagService(tagRepo)
+	viewService := services.NewViewService(viewRepo)
+	ruleService := services.NewRuleService(ruleRepo, categoryRepo, transactionRepo, suggestionService)
+	transactionService := services.NewTransactionService(transactionRepo, duplicateRepo, ruleService, suggestionService)
+	budgetService := services.NewBudgetService(budgetRepo, budgetTemplateRepo)
 	reportService := services.NewReportService(reportRepo)
 	savingsService := services.NewSavingsService(savingsRepo)
+	recurringService := services.NewRecurringService(recurringRepo, transactionRepo, categoryRepo, suggestionService)
+	transferService := services.NewTransferService(transferRepo, savingsRepo)
+	trashService := services.NewTrashService(trashRepo, suggestionService, trashRetention)
+	importService := services.NewImportService(transactionRepo, categoryRepo, importBatchRepo, duplicateRepo, ruleService, suggestionService)
 
 	// --- Crear handlers ---
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userRepo)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, viewService)
 	budgetHandler := handlers.NewBudgetHandler(budgetService)
-	reportHandler := handlers.NewReportHandler(reportService)
+	reportHandler := handlers.NewReportHandler(reportService, viewService)
 	savingsHandler := handlers.NewSavingsHandler(savingsService)
+	recurringHandler := handlers.NewRecurringHandler(recurringService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	importHandler := handlers.NewImportHandler(importService)
+	ruleHandler := handlers.NewRuleHandler(ruleService)
+	suggestionHandler := handlers.NewSuggestionHandler(suggestionService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	viewHandler := handlers.NewViewHandler(viewService, transactionService)
+	trashHandler := handlers.NewTrashHandler(trashService)
 
 	// ============================================
 	// RUTAS PÚBLICAS (no requieren JWT)
@@ -102,8 +138,12 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 		{
 			categories.GET("", categoryHandler.GetAll)
 			categories.POST("", categoryHandler.Create)
+			categories.GET("/suggest", suggestionHandler.Suggest)
+			categories.GET("/presets", categoryHandler.GetPresets)
+			categories.POST("/presets/:id/apply", categoryHandler.ApplyPreset)
 			categories.PUT("/:id", categoryHandler.Update)
 			categories.DELETE("/:id", categoryHandler.Delete)
+			categories.POST("/:id/merge", categoryHandler.Merge)
 		}
 
 		// Transacciones
@@ -113,7 +153,56 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 			transactions.POST("", transactionHandler.Create)
 			transactions.PUT("/:id", transactionHandler.Update)
 			transactions.DELETE("/:id", transactionHandler.Delete)
+			transactions.POST("/bulk", transactionHandler.Bulk)
 			transactions.GET("/export", transactionHandler.ExportCSV)
+			transactions.POST("/import", importHandler.Import)
+			transactions.GET("/duplicates", transactionHandler.GetDuplicates)
+			transactions.POST("/duplicates/dismiss", transactionHandler.DismissDuplicate)
+		}
+
+		// Etiquetas (varias por transacción)
+		tags := protected.Group("/tags")
+		{
+			tags.GET("", tagHandler.GetAll)
+			tags.POST("", tagHandler.Create)
+			tags.PUT("/:id", tagHandler.Update)
+			tags.DELETE("/:id", tagHandler.Delete)
+		}
+
+		// Vistas guardadas (filtros de transacciones con nombre)
+		views := protected.Group("/views")
+		{
+			views.GET("", viewHandler.GetAll)
+			views.POST("", viewHandler.Create)
+			views.PUT("/:id", viewHandler.Update)
+			views.DELETE("/:id", viewHandler.Delete)
+			views.GET("/:id/transactions", viewHandler.GetTransactions)
+		}
+
+		// Reglas de categorización automática
+		rules := protected.Group("/rules")
+		{
+			rules.GET("", ruleHandler.GetAll)
+			rules.POST("", ruleHandler.Create)
+			rules.PUT("/:id", ruleHandler.Update)
+			rules.DELETE("/:id", ruleHandler.Delete)
+			rules.POST("/apply", ruleHandler.Apply)
+		}
+
+		// Importaciones confirmadas (lotes que se pueden deshacer)
+		imports := protected.Group("/imports")
+		{
+			imports.GET("", importHandler.GetBatches)
+			imports.DELETE("/:id", importHandler.DeleteBatch)
+		}
+
+		// Transacciones recurrentes (plantillas que el worker materializa)
+		recurring := protected.Group("/recurring")
+		{
+			recurring.GET("", recurringHandler.GetAll)
+			recurring.POST("", recurringHandler.Create)
+			recurring.PUT("/:id", recurringHandler.Update)
+			recurring.DELETE("/:id", recurringHandler.Delete)
 		}
 
 		// Presupuestos
@@ -121,6 +210,12 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 		{
 			budgets.GET("", budgetHandler.GetByPeriod)
 			budgets.POST("", budgetHandler.Create)
+			budgets.POST("/copy", budgetHandler.Copy)
+			budgets.GET("/templates", budgetHandler.GetTemplates)
+			budgets.POST("/templates", budgetHandler.CreateTemplate)
+			budgets.PUT("/templates/:id", budgetHandler.UpdateTemplate)
+			budgets.DELETE("/templates/:id", budgetHandler.DeleteTemplate)
+			budgets.POST("/templates/:id/apply", budgetHandler.ApplyTemplate)
 			budgets.DELETE("/:id", budgetHandler.Delete)
 		}
 
@@ -129,6 +224,7 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 		{
 			reports.GET("/monthly", reportHandler.GetMonthly)
 			reports.GET("/yearly", reportHandler.GetYearly)
+			reports.GET("/tags", reportHandler.GetByTag)
 		}
 
 		// Cuentas de ahorro
@@ -138,9 +234,30 @@ func Setup(pool *pgxpool.Pool, jwtSecret string, corsOrigin string, resendAPIKey
 			savings.POST("", savingsHandler.Create)
 			savings.PUT("/:id", savingsHandler.Update)
 			savings.POST("/:id/adjust", savingsHandler.AdjustBalance)
+			savings.GET("/:id/movements", savingsHandler.GetMovements)
+			savings.GET("/:id/history", savingsHandler.GetHistory)
+			savings.GET("/:id/goal", savingsHandler.GetGoal)
 			savings.DELETE("/:id", savingsHandler.Delete)
 		}
+
+		// Papelera: lo borrado se puede restaurar hasta que se purga
+		trash := protected.Group("/trash")
+		{
+			trash.GET("", trashHandler.GetAll)
+			trash.POST("/:type/:id/restore", trashHandler.Restore)
+		}
+
+		// Transferencias entre flujo principal y cuentas de ahorro
+		transfers := protected.Group("/transfers")
+		{
+			transfers.POST("", transferHandler.Create)
+			transfers.DELETE("/:id", transferHandler.Delete)
+		}
 	}
 
-	return router
+	return router, &Services{
+		Recurring: recurringService,
+		Trash:     trashService,
+		Budget:    budgetService,
+	}
 }
@@ -14,45 +14,13 @@ import (
 
 	"expense-tracker-backend/internal/email"
 	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/presets"
 	"expense-tracker-backend/internal/repository"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Categorías predeterminadas que se crean para cada usuario nuevo.
-// Cada una tiene: nombre, color hex, ícono (clave de emoji), tipo.
-var defaultCategories = []struct {
-	Name  string
-	Color string
-	Icon  string
-	Type  string
-}{
-	// Gastos
-	{"Taxi", "#f97316", "taxi", "expense"},
-	{"Deportes", "#22c55e", "deportes", "expense"},
-	{"Entretenimiento", "#8b5cf6", "entretenimiento", "expense"},
-	{"Auto", "#3b82f6", "auto", "expense"},
-	{"Comida", "#ef4444", "comida", "expense"},
-	{"Casa", "#06b6d4", "casa", "expense"},
-	{"Facturas", "#64748b", "facturas", "expense"},
-	{"Higiene", "#ec4899", "higiene", "expense"},
-	{"Restaurante", "#f59e0b", "restaurante", "expense"},
-	{"Ropa", "#a855f7", "ropa", "expense"},
-	{"Salud", "#10b981", "salud", "expense"},
-	{"Transporte", "#0ea5e9", "transporte", "expense"},
-	{"Regalos", "#f43f5e", "regalos", "expense"},
-	{"Comunicaciones", "#6366f1", "comunicaciones", "expense"},
-	{"Suscripciones", "#7c3aed", "suscripciones", "expense"},
-	{"Mascotas", "#eab308", "mascotas", "expense"},
-	{"Ocio", "#14b8a6", "ocio", "expense"},
-	{"Maquillaje", "#f472b6", "maquillaje", "expense"},
-	{"Skincare", "#a78bfa", "skincare", "expense"},
-	// Ingresos
-	{"Salario", "#22c55e", "salario", "income"},
-	{"Depósito", "#0ea5e9", "deposito", "income"},
-}
-
 // AuthService contiene la lógica de autenticación: registro, login, generación de JWT,
 // y restablecimiento de contraseña con OTP.
 type AuthService struct {
@@ -82,8 +50,23 @@ func NewAuthService(
 }
 
 // Register crea un nuevo usuario. Hashea el password antes de guardarlo.
-// También crea las categorías predeterminadas para el usuario.
+// También le crea las categorías de los paquetes elegidos (o del predeterminado).
 func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error) {
+	// Verificar los paquetes antes de crear al usuario
+	presetIDs := req.Presets
+	if len(presetIDs) == 0 {
+		presetIDs = []string{presets.DefaultID}
+	}
+	for _, id := range presetIDs {
+		if _, ok := presets.Get(id); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPresetNotFound, id)
+		}
+	}
+	lang := req.Language
+	if lang == "" {
+		lang = presets.DefaultLanguage
+	}
+
 	// Verificar si el email ya existe
 	existing, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existing != nil {
@@ -102,11 +85,10 @@ func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest)
 		return nil, err
 	}
 
-	// Crear categorías predeterminadas para el usuario nuevo
-	for _, cat := range defaultCategories {
-		_, err := s.categoryRepo.Create(ctx, user.ID, cat.Name, cat.Color, cat.Icon, cat.Type)
-		if err != nil {
-			log.Printf("Error creando categoría predeterminada '%s': %v", cat.Name, err)
+	// Crear las categorías de los paquetes para el usuario nuevo
+	for _, id := range presetIDs {
+		if _, err := applyPreset(ctx, s.categoryRepo, user.ID, id, lang); err != nil {
+			log.Printf("Error aplicando el paquete de categorías '%s': %v", id, err)
 			// No fallar el registro por esto, solo log
 		}
 	}
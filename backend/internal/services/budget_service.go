@@ -2,25 +2,59 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 	"expense-tracker-backend/internal/repository"
 )
 
+// ErrSameBudgetPeriod se devuelve al copiar presupuestos de un periodo a ese mismo periodo.
+var ErrSameBudgetPeriod = errors.New("el periodo destino debe ser distinto del periodo origen")
+
+// ErrNoBudgetsToCopy se devuelve al copiar desde un periodo sin presupuestos.
+var ErrNoBudgetsToCopy = errors.New("el periodo origen no tiene presupuestos")
+
+// ErrInvalidBudgetPeriod se devuelve cuando las fechas no definen un periodo válido.
+var ErrInvalidBudgetPeriod = errors.New("periodo de presupuesto inválido")
+
+// ErrDuplicateTemplateCategory se devuelve cuando una plantilla repite una categoría en el mismo tipo de periodo.
+var ErrDuplicateTemplateCategory = errors.New("una plantilla no puede tener dos presupuestos para la misma categoría y tipo de periodo")
+
+// autoCopyPeriods son los tipos de periodo que copia la copia automática (custom no se repite).
+var autoCopyPeriods = []string{
+	models.BudgetPeriodWeekly,
+	models.BudgetPeriodMonthly,
+	models.BudgetPeriodQuarterly,
+	models.BudgetPeriodYearly,
+}
+
 type BudgetService struct {
-	budgetRepo *repository.BudgetRepository
+	budgetRepo   *repository.BudgetRepository
+	templateRepo *repository.BudgetTemplateRepository
 }
 
-func NewBudgetService(budgetRepo *repository.BudgetRepository) *BudgetService {
-	return &BudgetService{budgetRepo: budgetRepo}
+func NewBudgetService(budgetRepo *repository.BudgetRepository, templateRepo *repository.BudgetTemplateRepository) *BudgetService {
+	return &BudgetService{budgetRepo: budgetRepo, templateRepo: templateRepo}
 }
 
+// Upsert resuelve el periodo del presupuesto y lo crea o actualiza.
 func (s *BudgetService) Upsert(ctx context.Context, userID string, req models.CreateBudgetRequest) (*models.Budget, error) {
-	return s.budgetRepo.Upsert(ctx, userID, req)
+	if req.PeriodType == "" {
+		req.PeriodType = models.BudgetPeriodMonthly
+	}
+	start, end, err := resolveBudgetPeriod(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.Upsert(ctx, userID, req, start, end)
 }
 
-func (s *BudgetService) GetByPeriod(ctx context.Context, userID string, month, year int) ([]models.Budget, error) {
-	budgets, err := s.budgetRepo.GetByPeriod(ctx, userID, month, year)
+// GetByPeriod devuelve los presupuestos activos en algún día entre from y to (incluidos).
+func (s *BudgetService) GetByPeriod(ctx context.Context, userID string, from, to time.Time) ([]models.Budget, error) {
+	budgets, err := s.budgetRepo.GetByPeriod(ctx, userID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -33,3 +67,257 @@ func (s *BudgetService) GetByPeriod(ctx context.Context, userID string, month, y
 func (s *BudgetService) Delete(ctx context.Context, id, userID string) error {
 	return s.budgetRepo.Delete(ctx, id, userID)
 }
+
+// Copy copia los presupuestos de tipo req.PeriodType de un periodo a otro, con los límites
+// ajustados en req.AdjustPercent. Los que ya existen en el periodo destino solo se reemplazan
+// con req.Overwrite.
+func (s *BudgetService) Copy(ctx context.Context, userID string, req models.CopyBudgetsRequest) (*models.BudgetCopyResult, error) {
+	if req.PeriodType == "" {
+		req.PeriodType = models.BudgetPeriodMonthly
+	}
+	from, _, err := resolveBudgetPeriod(models.CreateBudgetRequest{
+		PeriodType: req.PeriodType, StartDate: req.FromStartDate, Month: req.FromMonth, Year: req.FromYear,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("periodo origen: %w", err)
+	}
+	to, toEnd, err := resolveBudgetPeriod(models.CreateBudgetRequest{
+		PeriodType: req.PeriodType, StartDate: req.ToStartDate, Month: req.ToMonth, Year: req.ToYear,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("periodo destino: %w", err)
+	}
+	if from.Equal(to) {
+		return nil, ErrSameBudgetPeriod
+	}
+
+	items, err := s.budgetRepo.GetItems(ctx, userID, req.PeriodType, from)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNoBudgetsToCopy
+	}
+
+	plans := make([]models.BudgetPlan, len(items))
+	for i, item := range items {
+		plans[i] = models.BudgetPlan{BudgetTemplateItem: item, StartDate: to, EndDate: toEnd}
+	}
+	return s.createMany(ctx, userID, plans, req.AdjustPercent, req.Overwrite)
+}
+
+// GetTemplates devuelve las plantillas de presupuestos del usuario.
+func (s *BudgetService) GetTemplates(ctx context.Context, userID string) ([]models.BudgetTemplate, error) {
+	templates, err := s.templateRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []models.BudgetTemplate{}
+	}
+	return templates, nil
+}
+
+// CreateTemplate valida y guarda una plantilla.
+func (s *BudgetService) CreateTemplate(ctx context.Context, userID string, req models.CreateBudgetTemplateRequest) (*models.BudgetTemplate, error) {
+	if err := checkTemplateItems(req.Items); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.Create(ctx, userID, req)
+}
+
+// UpdateTemplate renombra una plantilla o reemplaza sus ítems.
+func (s *BudgetService) UpdateTemplate(ctx context.Context, id, userID string, req models.UpdateBudgetTemplateRequest) (*models.BudgetTemplate, error) {
+	if req.Name == "" && req.Items == nil {
+		return nil, errors.New("no se proporcionaron campos para actualizar")
+	}
+	if err := checkTemplateItems(req.Items); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.Update(ctx, id, userID, req)
+}
+
+// DeleteTemplate elimina una plantilla. Los presupuestos creados con ella no cambian.
+func (s *BudgetService) DeleteTemplate(ctx context.Context, id, userID string) error {
+	return s.templateRepo.Delete(ctx, id, userID)
+}
+
+// ApplyTemplate crea los presupuestos de una plantilla: cada ítem en el periodo de su tipo
+// que contiene req.StartDate (o el día 1 de req.Month y req.Year). Con req.PeriodType solo
+// se aplican los ítems de ese tipo.
+func (s *BudgetService) ApplyTemplate(ctx context.Context, id, userID string, req models.ApplyBudgetTemplateRequest) (*models.BudgetCopyResult, error) {
+	var date time.Time
+	switch {
+	case req.StartDate != "":
+		var err error
+		date, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_date debe tener formato YYYY-MM-DD", ErrInvalidBudgetPeriod)
+		}
+	case req.Month != 0 && req.Year != 0:
+		date = time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return nil, fmt.Errorf("%w: start_date es requerido (o month y year)", ErrInvalidBudgetPeriod)
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]models.BudgetPlan, 0, len(template.Items))
+	for _, item := range template.Items {
+		if item.PeriodType == "" {
+			item.PeriodType = models.BudgetPeriodMonthly
+		}
+		if req.PeriodType != "" && item.PeriodType != req.PeriodType {
+			continue
+		}
+		start, end := budgetPeriodOf(item.PeriodType, date)
+		if err := checkBudgetDates(start, end); err != nil {
+			return nil, err
+		}
+		plans = append(plans, models.BudgetPlan{BudgetTemplateItem: item, StartDate: start, EndDate: end})
+	}
+	return s.createMany(ctx, userID, plans, req.AdjustPercent, req.Overwrite)
+}
+
+// AutoCopy copia, para cada tipo de periodo, los presupuestos del periodo anterior al
+// periodo actual de los usuarios que activaron la copia automática y todavía no lo tienen.
+// Devuelve cuántos presupuestos creó. El error de un usuario se registra y no detiene a los demás.
+func (s *BudgetService) AutoCopy(ctx context.Context, now time.Time) (int, error) {
+	total := 0
+	for _, periodType := range autoCopyPeriods {
+		start, end := budgetPeriodOf(periodType, now)
+		prevStart, _ := budgetPeriodOf(periodType, start.AddDate(0, 0, -1))
+
+		userIDs, err := s.budgetRepo.PendingAutoCopies(ctx, periodType, start)
+		if err != nil {
+			return total, err
+		}
+		for _, userID := range userIDs {
+			created, err := s.budgetRepo.AutoCopy(ctx, userID, periodType, prevStart, start, end)
+			if err != nil {
+				log.Printf("Error copiando presupuestos (%s) del usuario %s: %v", periodType, userID, err)
+				continue
+			}
+			total += created
+		}
+	}
+	return total, nil
+}
+
+// createMany crea los presupuestos de plans y devuelve, además de los conteos, los
+// presupuestos activos en el rango que cubren.
+func (s *BudgetService) createMany(ctx context.Context, userID string, plans []models.BudgetPlan, adjustPercent float64, overwrite bool) (*models.BudgetCopyResult, error) {
+	created, skipped, err := s.budgetRepo.CreateMany(ctx, userID, plans, 1+adjustPercent/100, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return &models.BudgetCopyResult{Created: created, Skipped: skipped, Budgets: []models.Budget{}}, nil
+	}
+
+	from, to := plans[0].StartDate, plans[0].EndDate
+	for _, plan := range plans[1:] {
+		if plan.StartDate.Before(from) {
+			from = plan.StartDate
+		}
+		if plan.EndDate.After(to) {
+			to = plan.EndDate
+		}
+	}
+	budgets, err := s.GetByPeriod(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BudgetCopyResult{Created: created, Skipped: skipped, Budgets: budgets}, nil
+}
+
+// checkTemplateItems completa el tipo de periodo de los ítems (vacío = monthly) y rechaza
+// plantillas que repiten una categoría en el mismo tipo de periodo.
+func checkTemplateItems(items []models.BudgetTemplateItem) error {
+	seen := make(map[string]bool, len(items))
+	for i := range items {
+		if items[i].PeriodType == "" {
+			items[i].PeriodType = models.BudgetPeriodMonthly
+		}
+		key := items[i].CategoryID + "|" + items[i].PeriodType
+		if seen[key] {
+			return ErrDuplicateTemplateCategory
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// resolveBudgetPeriod calcula las fechas del periodo de req. Salvo en custom, start_date
+// puede ser cualquier día del periodo y se lleva a su inicio; sin start_date se usa el
+// mes de month y year.
+func resolveBudgetPeriod(req models.CreateBudgetRequest) (time.Time, time.Time, error) {
+	var date time.Time
+	switch {
+	case req.StartDate != "":
+		var err error
+		date, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: start_date debe tener formato YYYY-MM-DD", ErrInvalidBudgetPeriod)
+		}
+	case req.Month != 0 && req.Year != 0 && req.PeriodType != models.BudgetPeriodCustom:
+		date = time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: start_date es requerido (o month y year para un presupuesto mensual)", ErrInvalidBudgetPeriod)
+	}
+
+	var start, end time.Time
+	if req.PeriodType == models.BudgetPeriodCustom {
+		if req.EndDate == "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: end_date es requerido en un periodo custom", ErrInvalidBudgetPeriod)
+		}
+		var err error
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: end_date debe tener formato YYYY-MM-DD", ErrInvalidBudgetPeriod)
+		}
+		if end.Before(date) {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: end_date no puede ser anterior a start_date", ErrInvalidBudgetPeriod)
+		}
+		start = date
+	} else {
+		start, end = budgetPeriodOf(req.PeriodType, date)
+	}
+
+	if err := checkBudgetDates(start, end); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// checkBudgetDates rechaza periodos fuera del rango de fechas permitido.
+func checkBudgetDates(start, end time.Time) error {
+	if start.Year() < 2020 || end.Year() > 2100 {
+		return fmt.Errorf("%w: las fechas deben estar entre 2020 y 2100", ErrInvalidBudgetPeriod)
+	}
+	return nil
+}
+
+// budgetPeriodOf devuelve el primer y el último día del periodo de calendario de tipo
+// periodType que contiene date (no aplica a custom).
+func budgetPeriodOf(periodType string, date time.Time) (time.Time, time.Time) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch periodType {
+	case models.BudgetPeriodWeekly:
+		// La semana empieza el lunes (time.Sunday es 0)
+		start := date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 6)
+	case models.BudgetPeriodQuarterly:
+		start := time.Date(date.Year(), date.Month()-(date.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, -1)
+	case models.BudgetPeriodYearly:
+		start := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+}
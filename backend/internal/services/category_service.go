@@ -2,98 +2,88 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/presets"
 	"expense-tracker-backend/internal/repository"
 )
 
-// Categorías predeterminadas (misma lista que en auth_service.go)
-var defaultCats = []struct {
-	Name  string
-	Color string
-	Icon  string
-	Type  string
-}{
-	{"Taxi", "#f97316", "taxi", "expense"},
-	{"Deportes", "#22c55e", "deportes", "expense"},
-	{"Entretenimiento", "#8b5cf6", "entretenimiento", "expense"},
-	{"Auto", "#3b82f6", "auto", "expense"},
-	{"Comida", "#ef4444", "comida", "expense"},
-	{"Casa", "#06b6d4", "casa", "expense"},
-	{"Facturas", "#64748b", "facturas", "expense"},
-	{"Higiene", "#ec4899", "higiene", "expense"},
-	{"Restaurante", "#f59e0b", "restaurante", "expense"},
-	{"Ropa", "#a855f7", "ropa", "expense"},
-	{"Salud", "#10b981", "salud", "expense"},
-	{"Transporte", "#0ea5e9", "transporte", "expense"},
-	{"Regalos", "#f43f5e", "regalos", "expense"},
-	{"Comunicaciones", "#6366f1", "comunicaciones", "expense"},
-	{"Suscripciones", "#7c3aed", "suscripciones", "expense"},
-	{"Mascotas", "#eab308", "mascotas", "expense"},
-	{"Ocio", "#14b8a6", "ocio", "expense"},
-	{"Maquillaje", "#f472b6", "maquillaje", "expense"},
-	{"Skincare", "#a78bfa", "skincare", "expense"},
-	{"Salario", "#22c55e", "salario", "income"},
-	{"Depósito", "#0ea5e9", "deposito", "income"},
-}
+// ErrPresetNotFound se devuelve al pedir un paquete de categorías que no existe.
+var ErrPresetNotFound = errors.New("paquete de categorías no encontrado")
+
+// ErrUnsupportedLanguage se devuelve cuando el idioma pedido no es uno de presets.Languages.
+var ErrUnsupportedLanguage = errors.New("idioma no soportado: usa es o en")
 
 type CategoryService struct {
 	categoryRepo *repository.CategoryRepository
+	suggestions  *SuggestionService
 }
 
-func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
-	return &CategoryService{categoryRepo: categoryRepo}
-}
-
-// íconos de las categorías nuevas que queremos asegurar para todos (incluso usuarios existentes)
-var newDefaultIcons = map[string]struct{ Name, Color string }{
-	"mascotas":  {"Mascotas", "#eab308"},
-	"ocio":      {"Ocio", "#14b8a6"},
-	"maquillaje": {"Maquillaje", "#f472b6"},
-	"skincare":  {"Skincare", "#a78bfa"},
+func NewCategoryService(categoryRepo *repository.CategoryRepository, suggestions *SuggestionService) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo, suggestions: suggestions}
 }
 
-func (s *CategoryService) GetAll(ctx context.Context, userID string) ([]models.Category, error) {
-	categories, err := s.categoryRepo.GetAllByUser(ctx, userID)
+// GetAll devuelve las categorías del usuario; las archivadas solo con includeArchived.
+// Con tree, devuelve solo las principales, cada una con sus subcategorías en Children.
+func (s *CategoryService) GetAll(ctx context.Context, userID string, includeArchived, tree bool) ([]models.Category, error) {
+	categories, err := s.categoryRepo.GetAllByUser(ctx, userID, true)
 	if err != nil {
 		return nil, err
 	}
 
-	// Si el usuario no tiene categorías, crear todas las predeterminadas
+	// Si el usuario no tiene categorías, aplicar el paquete predeterminado
 	if len(categories) == 0 {
-		log.Printf("Usuario %s sin categorías, creando predeterminadas...", userID)
-		for _, cat := range defaultCats {
-			created, err := s.categoryRepo.Create(ctx, userID, cat.Name, cat.Color, cat.Icon, cat.Type)
-			if err != nil {
-				log.Printf("Error creando categoría '%s': %v", cat.Name, err)
-				continue
-			}
-			categories = append(categories, *created)
+		log.Printf("Usuario %s sin categorías, aplicando el paquete %s...", userID, presets.DefaultID)
+		applied, err := applyPreset(ctx, s.categoryRepo, userID, presets.DefaultID, presets.DefaultLanguage)
+		if err != nil {
+			log.Printf("Error aplicando el paquete %s: %v", presets.DefaultID, err)
+		} else {
+			categories = applied.Created
+		}
+	}
+
+	visible := make([]models.Category, 0, len(categories))
+	for _, c := range categories {
+		if includeArchived || !c.Archived {
+			visible = append(visible, c)
 		}
-	} else {
-		// Usuario existente: asegurar que tenga las categorías nuevas (Mascotas, Ocio, Maquillaje, Skincare)
-		hasIcon := make(map[string]bool)
-		for _, c := range categories {
-			hasIcon[c.Icon] = true
+	}
+	if tree {
+		if roots := categoryTree(visible); roots != nil {
+			return roots, nil
 		}
-		for icon, info := range newDefaultIcons {
-			if !hasIcon[icon] {
-				created, err := s.categoryRepo.Create(ctx, userID, info.Name, info.Color, icon, "expense")
-				if err != nil {
-					log.Printf("Error creando categoría '%s' para usuario existente: %v", info.Name, err)
-					continue
-				}
-				categories = append(categories, *created)
-				hasIcon[icon] = true
-			}
+	}
+	return visible, nil
+}
+
+// categoryTree arma el árbol de categorías a partir de la lista plana (que viene ordenada
+// por tipo y nombre, así que cada nivel queda en ese orden). Una subcategoría cuyo padre
+// no está en la lista (ej: el padre está archivado) aparece como principal.
+func categoryTree(categories []models.Category) []models.Category {
+	present := make(map[string]bool, len(categories))
+	children := make(map[string][]models.Category)
+	for _, c := range categories {
+		present[c.ID] = true
+	}
+	var roots []models.Category
+	for _, c := range categories {
+		if c.ParentID != nil && present[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
 		}
 	}
 
-	if categories == nil {
-		categories = []models.Category{}
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
 	}
-	return categories, nil
+	return attach(roots)
 }
 
 func (s *CategoryService) GetByID(ctx context.Context, id, userID string) (*models.Category, error) {
@@ -101,13 +91,112 @@ func (s *CategoryService) GetByID(ctx context.Context, id, userID string) (*mode
 }
 
 func (s *CategoryService) Create(ctx context.Context, userID string, req models.CreateCategoryRequest) (*models.Category, error) {
-	return s.categoryRepo.Create(ctx, userID, req.Name, req.Color, req.Icon, req.Type)
+	return s.categoryRepo.Create(ctx, userID, req.Name, req.Color, req.Icon, req.Type, req.ParentID)
 }
 
 func (s *CategoryService) Update(ctx context.Context, id, userID string, req models.UpdateCategoryRequest) (*models.Category, error) {
-	return s.categoryRepo.Update(ctx, id, userID, req.Name, req.Nickname, req.Color, req.Icon)
+	return s.categoryRepo.Update(ctx, id, userID, req.Name, req.Nickname, req.Color, req.Icon, req.Archived, req.ParentID)
+}
+
+// Merge fusiona la categoría id en req.Into (deben ser del mismo tipo) y elimina id.
+func (s *CategoryService) Merge(ctx context.Context, id, userID string, req models.MergeCategoryRequest) (*models.Category, error) {
+	target, err := s.categoryRepo.Merge(ctx, id, req.Into, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Las transacciones cambiaron de categoría: el clasificador se reconstruye
+	s.suggestions.Invalidate(userID)
+	return target, nil
 }
 
-func (s *CategoryService) Delete(ctx context.Context, id, userID string) error {
-	return s.categoryRepo.Delete(ctx, id, userID)
+// Delete manda una categoría a la papelera. Con reassignTo, sus transacciones, recurrentes,
+// reglas y presupuestos pasan antes a esa otra categoría del mismo tipo.
+func (s *CategoryService) Delete(ctx context.Context, id, userID, reassignTo string) error {
+	if err := s.categoryRepo.Delete(ctx, id, userID, reassignTo); err != nil {
+		return err
+	}
+	// Sus transacciones pasaron a otra categoría (o la categoría ya no se puede sugerir)
+	s.suggestions.Invalidate(userID)
+	return nil
+}
+
+// GetPresets devuelve los paquetes de categorías con los textos en lang ("" = español),
+// indicando qué versión de cada uno aplicó ya el usuario.
+func (s *CategoryService) GetPresets(ctx context.Context, userID, lang string) ([]models.CategoryPreset, error) {
+	if lang == "" {
+		lang = presets.DefaultLanguage
+	}
+	if !presets.SupportedLanguage(lang) {
+		return nil, ErrUnsupportedLanguage
+	}
+
+	applied, err := s.categoryRepo.GetAppliedPresets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.CategoryPreset{}
+	for _, pack := range presets.All() {
+		preset := localizePreset(pack, lang)
+		if version, ok := applied[pack.ID]; ok {
+			preset.AppliedVersion = &version
+		}
+		result = append(result, preset)
+	}
+	return result, nil
+}
+
+// ApplyPreset crea las categorías del paquete id que el usuario no tiene, con los
+// nombres en lang ("" = español). Aplicarlo otra vez solo agrega lo que falte.
+func (s *CategoryService) ApplyPreset(ctx context.Context, userID, id, lang string) (*models.ApplyPresetResponse, error) {
+	if lang == "" {
+		lang = presets.DefaultLanguage
+	}
+	return applyPreset(ctx, s.categoryRepo, userID, id, lang)
+}
+
+// applyPreset aplica un paquete al usuario. Lo usan el registro y CategoryService.
+func applyPreset(ctx context.Context, categoryRepo *repository.CategoryRepository, userID, id, lang string) (*models.ApplyPresetResponse, error) {
+	pack, ok := presets.Get(id)
+	if !ok {
+		return nil, ErrPresetNotFound
+	}
+	if !presets.SupportedLanguage(lang) {
+		return nil, ErrUnsupportedLanguage
+	}
+
+	preset := localizePreset(pack, lang)
+	created, existing, err := categoryRepo.ApplyPreset(ctx, userID, pack.ID, pack.Version, preset.Categories)
+	if err != nil {
+		return nil, err
+	}
+	preset.AppliedVersion = &pack.Version
+	return &models.ApplyPresetResponse{Preset: preset, Created: created, Existing: existing}, nil
+}
+
+// localizePreset arma la vista de un paquete con los textos en lang.
+func localizePreset(pack presets.Pack, lang string) models.CategoryPreset {
+	preset := models.CategoryPreset{
+		ID:          pack.ID,
+		Version:     pack.Version,
+		Name:        pack.Name.In(lang),
+		Description: pack.Description.In(lang),
+		Categories:  make([]models.PresetCategory, len(pack.Categories)),
+	}
+	for i, c := range pack.Categories {
+		names := make([]string, 0, len(presets.Languages))
+		for _, l := range presets.Languages {
+			names = append(names, c.Name[l])
+		}
+		preset.Categories[i] = models.PresetCategory{
+			Key:       c.Key,
+			Name:      c.Name.In(lang),
+			Color:     c.Color,
+			Icon:      c.Icon,
+			Type:      c.Type,
+			ParentKey: c.Parent,
+			Names:     names,
+		}
+	}
+	return preset
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/textutil"
+)
+
+// Parámetros del detector de duplicados.
+const (
+	// DefaultDuplicateWindowDays: dos movimientos iguales a más de 3 días se consideran distintos.
+	DefaultDuplicateWindowDays = 3
+	// MaxDuplicateWindowDays limita la ventana que se puede pedir en la revisión.
+	MaxDuplicateWindowDays = 30
+	// duplicateSimilarity es el parecido mínimo de descripciones para marcar un duplicado.
+	duplicateSimilarity = 0.6
+)
+
+// duplicateProbe es lo que se compara de una transacción nueva (creada o importada).
+type duplicateProbe struct {
+	ID          string // Vacío si aún no existe (filas de un import)
+	Type        string
+	Amount      float64
+	Date        time.Time
+	Description string
+}
+
+// matchDuplicates devuelve las transacciones existentes que parecen ser la misma que probe:
+// mismo tipo, mismo monto (al centavo), fechas a no más de windowDays días y descripción parecida.
+func matchDuplicates(probe duplicateProbe, existing []models.Transaction, windowDays int) []models.Transaction {
+	var matches []models.Transaction
+	for _, t := range existing {
+		if t.ID == probe.ID || t.Type != probe.Type || !sameAmount(t.Amount, probe.Amount) {
+			continue
+		}
+		if daysApart(t.Date, probe.Date) > windowDays {
+			continue
+		}
+		if textutil.Similarity(t.Description, probe.Description) < duplicateSimilarity {
+			continue
+		}
+		matches = append(matches, t)
+	}
+	return matches
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
+
+// daysApart devuelve la diferencia en días entre dos fechas (siempre positiva).
+func daysApart(a, b time.Time) int {
+	days := int(math.Round(a.Sub(b).Hours() / 24))
+	if days < 0 {
+		days = -days
+	}
+	return days
+}
@@ -0,0 +1,328 @@
+// Service de importación — convierte extractos bancarios en transacciones.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"expense-tracker-backend/internal/importer"
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/ofx"
+	"expense-tracker-backend/internal/repository"
+	"expense-tracker-backend/internal/textutil"
+)
+
+// ErrImportHasErrors se devuelve al confirmar un import con filas inválidas sin skip_invalid.
+var ErrImportHasErrors = errors.New("el archivo tiene filas con errores; corrígelas o usa skip_invalid")
+
+type ImportService struct {
+	transactionRepo *repository.TransactionRepository
+	categoryRepo    *repository.CategoryRepository
+	importBatchRepo *repository.ImportBatchRepository
+	duplicateRepo   *repository.DuplicateRepository
+	ruleService     *RuleService
+	suggestions     *SuggestionService
+}
+
+func NewImportService(
+	transactionRepo *repository.TransactionRepository,
+	categoryRepo *repository.CategoryRepository,
+	importBatchRepo *repository.ImportBatchRepository,
+	duplicateRepo *repository.DuplicateRepository,
+	ruleService *RuleService,
+	suggestions *SuggestionService,
+) *ImportService {
+	return &ImportService{
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		importBatchRepo: importBatchRepo,
+		duplicateRepo:   duplicateRepo,
+		ruleService:     ruleService,
+		suggestions:     suggestions,
+	}
+}
+
+// Formatos de archivo soportados por Import.
+const (
+	ImportFormatCSV = "csv"
+	ImportFormatOFX = "ofx"
+)
+
+// Import interpreta el archivo y asigna categorías. Con dryRun solo devuelve la vista previa;
+// sin dryRun guarda todas las filas válidas en una sola transacción de base de datos
+// y las registra como un lote (import_batches) que se puede deshacer con DeleteBatch.
+// Si hay filas con errores y skipInvalid es false, no se guarda nada.
+// Las filas cuyo ID externo (FITID del OFX) ya se importó se marcan como duplicadas y se omiten.
+// Las que se parecen a una transacción existente quedan con PossibleDuplicates y solo se
+// omiten si skipDuplicates es true.
+func (s *ImportService) Import(ctx context.Context, userID, format, filename string, data []byte, mapping models.CSVImportMapping, dryRun, skipInvalid, skipDuplicates bool) (*models.ImportPreview, error) {
+	if format == "" {
+		format = ImportFormatCSV
+		if ofx.IsOFX(data) {
+			format = ImportFormatOFX
+		}
+	}
+
+	var rows []models.ImportRow
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = importer.ParseCSV(data, mapping)
+	case ImportFormatOFX:
+		var stmtCurrency string
+		rows, stmtCurrency, err = importer.ParseOFX(data)
+		if mapping.Currency == "" {
+			mapping.Currency = stmtCurrency
+		}
+	default:
+		return nil, fmt.Errorf("formato de archivo desconocido: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolveCategories(ctx, userID, rows, mapping); err != nil {
+		return nil, err
+	}
+	if err := s.markDuplicates(ctx, userID, rows); err != nil {
+		return nil, err
+	}
+	if err := s.markPossibleDuplicates(ctx, userID, rows); err != nil {
+		return nil, err
+	}
+
+	preview := &models.ImportPreview{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Rows:      rows,
+	}
+	if preview.Rows == nil {
+		preview.Rows = []models.ImportRow{}
+	}
+
+	currency := strings.ToUpper(mapping.Currency)
+	if currency == "" {
+		currency = "COP"
+	}
+
+	var reqs []models.CreateTransactionRequest
+	for _, row := range rows {
+		if row.Duplicate {
+			preview.DuplicateRows++
+			continue
+		}
+		if len(row.Errors) > 0 {
+			preview.ErrorRows++
+			continue
+		}
+		if len(row.PossibleDuplicates) > 0 {
+			preview.PossibleDuplicateRows++
+			if skipDuplicates {
+				continue
+			}
+		}
+		preview.ValidRows++
+		reqs = append(reqs, models.CreateTransactionRequest{
+			CategoryID:  row.CategoryID,
+			Amount:      row.Amount,
+			Type:        row.Type,
+			Description: row.Description,
+			Date:        row.Date,
+			Currency:    currency,
+			ExternalID:  row.ExternalID,
+		})
+	}
+
+	if dryRun {
+		return preview, nil
+	}
+	if preview.ErrorRows > 0 && !skipInvalid {
+		return preview, ErrImportHasErrors
+	}
+	if len(reqs) == 0 {
+		return preview, nil
+	}
+
+	batch, err := s.importBatchRepo.Create(ctx, userID, format, filename, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if batch != nil {
+		preview.Imported = batch.RowCount
+		preview.BatchID = batch.ID
+		s.suggestions.Invalidate(userID)
+	}
+	return preview, nil
+}
+
+// markPossibleDuplicates busca, para cada fila válida, transacciones existentes que parezcan la misma.
+// Se cargan de una vez todas las transacciones del rango de fechas del archivo (± la ventana).
+func (s *ImportService) markPossibleDuplicates(ctx context.Context, userID string, rows []models.ImportRow) error {
+	window := DefaultDuplicateWindowDays
+	var first, last time.Time
+	dates := make([]time.Time, len(rows))
+	for i, row := range rows {
+		if row.Duplicate || len(row.Errors) > 0 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", row.Date)
+		if err != nil {
+			continue
+		}
+		dates[i] = date
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	existing, err := s.duplicateRepo.GetInRange(ctx, userID,
+		first.AddDate(0, 0, -window).Format("2006-01-02"),
+		last.AddDate(0, 0, window).Format("2006-01-02"),
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		if dates[i].IsZero() {
+			continue
+		}
+		rows[i].PossibleDuplicates = matchDuplicates(duplicateProbe{
+			Type:        rows[i].Type,
+			Amount:      rows[i].Amount,
+			Date:        dates[i],
+			Description: rows[i].Description,
+		}, existing, window)
+	}
+	return nil
+}
+
+// GetBatches devuelve los imports confirmados del usuario.
+func (s *ImportService) GetBatches(ctx context.Context, userID string) ([]models.ImportBatch, error) {
+	batches, err := s.importBatchRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if batches == nil {
+		batches = []models.ImportBatch{}
+	}
+	return batches, nil
+}
+
+// DeleteBatch deshace un import completo. Devuelve cuántas transacciones se eliminaron.
+func (s *ImportService) DeleteBatch(ctx context.Context, id, userID string) (int64, error) {
+	deleted, err := s.importBatchRepo.Delete(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+	s.suggestions.Invalidate(userID)
+	return deleted, nil
+}
+
+// resolveCategories asigna a cada fila una categoría del usuario del mismo tipo.
+// Primero busca el texto de la columna de categoría por nombre o alias (sin tildes ni mayúsculas);
+// si no aparece, prueba las reglas de categorización y al final la categoría por defecto del mapeo.
+func (s *ImportService) resolveCategories(ctx context.Context, userID string, rows []models.ImportRow, mapping models.CSVImportMapping) error {
+	categories, err := s.categoryRepo.GetAllByUser(ctx, userID, false)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]models.Category, len(categories))
+	byName := make(map[string]models.Category)
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+		byName[cat.Type+"|"+textutil.Normalize(cat.Name)] = cat
+		if cat.Nickname != "" {
+			byName[cat.Type+"|"+textutil.Normalize(cat.Nickname)] = cat
+		}
+	}
+
+	rules, err := s.ruleService.matcher(ctx, userID, nil)
+	if err != nil {
+		return err
+	}
+
+	defaults := map[string]string{
+		"income":  mapping.DefaultIncomeCategoryID,
+		"expense": mapping.DefaultExpenseCategoryID,
+	}
+	for txType, id := range defaults {
+		if id == "" {
+			continue
+		}
+		cat, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("la categoría por defecto %s no existe", id)
+		}
+		if cat.Type != txType {
+			return fmt.Errorf("la categoría por defecto '%s' es de tipo %s, no %s", cat.Name, cat.Type, txType)
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Type == "" {
+			continue // La fila ya tiene error de monto
+		}
+
+		if row.CategoryRaw != "" {
+			if cat, ok := byName[row.Type+"|"+textutil.Normalize(row.CategoryRaw)]; ok {
+				row.CategoryID, row.CategoryName = cat.ID, cat.Name
+				continue
+			}
+		}
+		if rule := rules.match(row.Type, row.Description, row.Amount); rule != nil {
+			row.CategoryID, row.CategoryName, row.RuleID = rule.CategoryID, byID[rule.CategoryID].Name, rule.ID
+			continue
+		}
+		if id := defaults[row.Type]; id != "" {
+			row.CategoryID, row.CategoryName = id, byID[id].Name
+			continue
+		}
+
+		if row.CategoryRaw != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("no existe una categoría de tipo %s llamada '%s'", row.Type, row.CategoryRaw))
+		} else {
+			row.Errors = append(row.Errors, fmt.Sprintf("la fila no tiene categoría y no hay categoría por defecto para %s", row.Type))
+		}
+	}
+	return nil
+}
+
+// markDuplicates marca las filas cuyo ID externo ya existe para el usuario.
+func (s *ImportService) markDuplicates(ctx context.Context, userID string, rows []models.ImportRow) error {
+	var ids []string
+	for _, row := range rows {
+		if row.ExternalID != "" {
+			ids = append(ids, row.ExternalID)
+		}
+	}
+	existing, err := s.transactionRepo.GetExistingExternalIDs(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+
+	// También dentro del mismo archivo: algunos bancos repiten movimientos entre extractos unidos
+	seen := make(map[string]bool, len(ids))
+	for i := range rows {
+		id := rows[i].ExternalID
+		if id == "" {
+			continue
+		}
+		if existing[id] || seen[id] {
+			rows[i].Duplicate = true
+		}
+		seen[id] = true
+	}
+	return nil
+}
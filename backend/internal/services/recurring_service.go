@@ -0,0 +1,234 @@
+// Service de transacciones recurrentes — calendario y materialización.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+// maxOccurrencesPerRun limita cuántas ocurrencias se crean por plantilla en una pasada.
+// Evita que una plantilla diaria con start_date muy antigua bloquee el worker.
+const maxOccurrencesPerRun = 400
+
+type RecurringService struct {
+	recurringRepo   *repository.RecurringRepository
+	transactionRepo *repository.TransactionRepository
+	categoryRepo    *repository.CategoryRepository
+	suggestions     *SuggestionService
+}
+
+func NewRecurringService(
+	recurringRepo *repository.RecurringRepository,
+	transactionRepo *repository.TransactionRepository,
+	categoryRepo *repository.CategoryRepository,
+	suggestions *SuggestionService,
+) *RecurringService {
+	return &RecurringService{
+		recurringRepo:   recurringRepo,
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		suggestions:     suggestions,
+	}
+}
+
+// GetAll devuelve las plantillas recurrentes del usuario.
+func (s *RecurringService) GetAll(ctx context.Context, userID string) ([]models.RecurringTransaction, error) {
+	items, err := s.recurringRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.RecurringTransaction{}
+	}
+	return items, nil
+}
+
+// Create valida el calendario y guarda la plantilla con su primera fecha de ejecución.
+func (s *RecurringService) Create(ctx context.Context, userID string, req models.CreateRecurringRequest) (*models.RecurringTransaction, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("start_date debe tener formato YYYY-MM-DD")
+	}
+	if req.EndDate != "" {
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, errors.New("end_date debe tener formato YYYY-MM-DD")
+		}
+		if end.Before(start) {
+			return nil, errors.New("end_date no puede ser anterior a start_date")
+		}
+	}
+
+	if err := s.checkCategory(ctx, userID, req.CategoryID, req.Type); err != nil {
+		return nil, err
+	}
+
+	var dayOfMonth *int
+	if req.Frequency == "monthly" {
+		day := req.DayOfMonth
+		if day == 0 {
+			day = start.Day()
+		}
+		dayOfMonth = &day
+	}
+
+	rt := &models.RecurringTransaction{
+		Frequency:  req.Frequency,
+		DayOfMonth: dayOfMonth,
+		StartDate:  start,
+	}
+	return s.recurringRepo.Create(ctx, userID, req, dayOfMonth, firstOccurrence(rt))
+}
+
+// Update modifica la plantilla y, si se pide, propaga los cambios a las transacciones ya generadas.
+func (s *RecurringService) Update(ctx context.Context, id, userID string, req models.UpdateRecurringRequest) (*models.RecurringTransaction, error) {
+	existing, err := s.recurringRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != "" {
+		if err := s.checkCategory(ctx, userID, req.CategoryID, existing.Type); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		end, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			return nil, errors.New("end_date debe tener formato YYYY-MM-DD")
+		}
+		if end.Before(existing.StartDate) {
+			return nil, errors.New("end_date no puede ser anterior a start_date")
+		}
+	}
+	if req.PropagateFrom != "" {
+		if _, err := time.Parse("2006-01-02", req.PropagateFrom); err != nil {
+			return nil, errors.New("propagate_from debe tener formato YYYY-MM-DD")
+		}
+	}
+
+	updated, n, err := s.recurringRepo.Update(ctx, id, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.PropagateFrom != "" {
+		log.Printf("Recurrente %s: cambios propagados a %d transacciones", id, n)
+		if n > 0 {
+			s.suggestions.Invalidate(userID)
+		}
+	}
+
+	return updated, nil
+}
+
+// Delete elimina una plantilla (las transacciones generadas se conservan).
+func (s *RecurringService) Delete(ctx context.Context, id, userID string) error {
+	return s.recurringRepo.Delete(ctx, id, userID)
+}
+
+// MaterializeDue crea todas las ocurrencias pendientes hasta "today" (inclusive).
+// Es idempotente: si una ocurrencia ya existe (índice único recurring_id + date),
+// simplemente se salta y se avanza la fecha. Devuelve cuántas transacciones se crearon.
+func (s *RecurringService) MaterializeDue(ctx context.Context, today time.Time) (int, error) {
+	due, err := s.recurringRepo.GetDue(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i := range due {
+		rt := &due[i]
+		date := rt.NextRunDate
+		for n := 0; n < maxOccurrencesPerRun; n++ {
+			if date.After(today) || (rt.EndDate != nil && date.After(*rt.EndDate)) {
+				break
+			}
+
+			t, err := s.transactionRepo.Create(ctx, rt.UserID, models.CreateTransactionRequest{
+				CategoryID:  rt.CategoryID,
+				Amount:      rt.Amount,
+				Type:        rt.Type,
+				Description: rt.Description,
+				Date:        date.Format("2006-01-02"),
+				Currency:    rt.Currency,
+				RecurringID: rt.ID,
+			})
+			if err != nil && !repository.IsUniqueViolation(err) {
+				log.Printf("Recurrente %s: error creando ocurrencia %s: %v", rt.ID, date.Format("2006-01-02"), err)
+				break
+			}
+			if err == nil {
+				created++
+				s.suggestions.Learn(rt.UserID, t)
+			}
+
+			date = nextOccurrence(rt, date)
+			if err := s.recurringRepo.SetNextRun(ctx, rt.ID, date); err != nil {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
+
+// checkCategory verifica que la categoría sea del usuario y del mismo tipo que la plantilla.
+func (s *RecurringService) checkCategory(ctx context.Context, userID, categoryID, txType string) error {
+	cat, err := s.categoryRepo.GetByID(ctx, categoryID, userID)
+	if err != nil {
+		return err
+	}
+	if cat.Type != txType {
+		return fmt.Errorf("la categoría '%s' es de tipo %s, no %s", cat.Name, cat.Type, txType)
+	}
+	return nil
+}
+
+// firstOccurrence calcula la primera fecha de la plantilla a partir de start_date.
+func firstOccurrence(rt *models.RecurringTransaction) time.Time {
+	start := rt.StartDate
+	if rt.Frequency != "monthly" || rt.DayOfMonth == nil {
+		return start
+	}
+	candidate := dateInMonth(start.Year(), start.Month(), *rt.DayOfMonth)
+	if candidate.Before(start) {
+		next := start.AddDate(0, 0, 1-start.Day()).AddDate(0, 1, 0)
+		candidate = dateInMonth(next.Year(), next.Month(), *rt.DayOfMonth)
+	}
+	return candidate
+}
+
+// nextOccurrence devuelve la ocurrencia siguiente a "from" según la frecuencia.
+// Para mensual y anual se respeta el día original aunque un mes intermedio sea más corto
+// (ej: día 31 → 28 feb → 31 mar).
+func nextOccurrence(rt *models.RecurringTransaction, from time.Time) time.Time {
+	switch rt.Frequency {
+	case "daily":
+		return from.AddDate(0, 0, 1)
+	case "weekly":
+		return from.AddDate(0, 0, 7)
+	case "monthly":
+		day := from.Day()
+		if rt.DayOfMonth != nil {
+			day = *rt.DayOfMonth
+		}
+		firstOfNext := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		return dateInMonth(firstOfNext.Year(), firstOfNext.Month(), day)
+	default: // yearly
+		return dateInMonth(from.Year()+1, rt.StartDate.Month(), rt.StartDate.Day())
+	}
+}
+
+// dateInMonth construye la fecha year-month-day ajustando el día al último del mes si no existe.
+func dateInMonth(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -15,10 +15,27 @@ func NewReportService(reportRepo *repository.ReportRepository) *ReportService {
 	return &ReportService{reportRepo: reportRepo}
 }
 
-func (s *ReportService) GetMonthlySummary(ctx context.Context, userID string, month, year int) (*models.MonthlySummary, error) {
-	return s.reportRepo.GetMonthlySummary(ctx, userID, month, year)
+// Los reportes reciben un scope opcional (el filtro de una vista guardada): si no es nil,
+// solo suman las transacciones que lo cumplen.
+
+// GetMonthlySummary devuelve el resumen del mes. Con rollup, el desglose agrupa cada
+// subcategoría en su categoría principal.
+func (s *ReportService) GetMonthlySummary(ctx context.Context, userID string, month, year int, scope *models.TransactionFilter, rollup bool) (*models.MonthlySummary, error) {
+	return s.reportRepo.GetMonthlySummary(ctx, userID, month, year, scope, rollup)
+}
+
+func (s *ReportService) GetYearlySummary(ctx context.Context, userID string, year int, scope *models.TransactionFilter) (*models.YearlySummary, error) {
+	return s.reportRepo.GetYearlySummary(ctx, userID, year, scope)
 }
 
-func (s *ReportService) GetYearlySummary(ctx context.Context, userID string, year int) (*models.YearlySummary, error) {
-	return s.reportRepo.GetYearlySummary(ctx, userID, year)
+// GetTagReport devuelve los totales por etiqueta entre dos fechas (opcionales).
+func (s *ReportService) GetTagReport(ctx context.Context, userID, dateFrom, dateTo string, scope *models.TransactionFilter) (*models.TagReport, error) {
+	totals, err := s.reportRepo.GetTagTotals(ctx, userID, dateFrom, dateTo, scope)
+	if err != nil {
+		return nil, err
+	}
+	if totals == nil {
+		totals = []models.TagSummary{}
+	}
+	return &models.TagReport{DateFrom: dateFrom, DateTo: dateTo, Tags: totals}, nil
 }
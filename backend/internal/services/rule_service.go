@@ -0,0 +1,277 @@
+// Service de reglas de categorización — validación, evaluación y aplicación retroactiva.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+	"expense-tracker-backend/internal/textutil"
+)
+
+type RuleService struct {
+	ruleRepo        *repository.RuleRepository
+	categoryRepo    *repository.CategoryRepository
+	transactionRepo *repository.TransactionRepository
+	suggestions     *SuggestionService
+}
+
+func NewRuleService(
+	ruleRepo *repository.RuleRepository,
+	categoryRepo *repository.CategoryRepository,
+	transactionRepo *repository.TransactionRepository,
+	suggestions *SuggestionService,
+) *RuleService {
+	return &RuleService{
+		ruleRepo:        ruleRepo,
+		categoryRepo:    categoryRepo,
+		transactionRepo: transactionRepo,
+		suggestions:     suggestions,
+	}
+}
+
+// GetAll devuelve las reglas del usuario en orden de evaluación.
+func (s *RuleService) GetAll(ctx context.Context, userID string) ([]models.CategorizationRule, error) {
+	rules, err := s.ruleRepo.GetAllByUser(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []models.CategorizationRule{}
+	}
+	return rules, nil
+}
+
+// Create valida y guarda una regla. El tipo de la regla es el de su categoría.
+func (s *RuleService) Create(ctx context.Context, userID string, req models.CreateRuleRequest) (*models.CategorizationRule, error) {
+	cat, err := s.categoryRepo.GetByID(ctx, req.CategoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Type != "" && req.Type != cat.Type {
+		return nil, fmt.Errorf("la categoría '%s' es de tipo %s, no %s", cat.Name, cat.Type, req.Type)
+	}
+	if err := validatePattern(req.MatchType, req.Pattern); err != nil {
+		return nil, err
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return nil, errors.New("min_amount no puede ser mayor que max_amount")
+	}
+
+	return s.ruleRepo.Create(ctx, userID, req, cat.Type)
+}
+
+// Update valida y modifica una regla existente.
+func (s *RuleService) Update(ctx context.Context, id, userID string, req models.UpdateRuleRequest) (*models.CategorizationRule, error) {
+	existing, err := s.ruleRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleType := existing.Type
+	if req.CategoryID != "" {
+		cat, err := s.categoryRepo.GetByID(ctx, req.CategoryID, userID)
+		if err != nil {
+			return nil, err
+		}
+		ruleType = cat.Type
+	}
+
+	matchType, pattern := existing.MatchType, existing.Pattern
+	if req.MatchType != "" {
+		matchType = req.MatchType
+	}
+	if req.Pattern != "" {
+		pattern = req.Pattern
+	}
+	if err := validatePattern(matchType, pattern); err != nil {
+		return nil, err
+	}
+
+	// Validar el rango con los valores finales (0 = sin límite)
+	minAmount, maxAmount := existing.MinAmount, existing.MaxAmount
+	if req.MinAmount != nil {
+		minAmount = req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		maxAmount = req.MaxAmount
+	}
+	if minAmount != nil && maxAmount != nil && *maxAmount > 0 && *minAmount > *maxAmount {
+		return nil, errors.New("min_amount no puede ser mayor que max_amount")
+	}
+
+	return s.ruleRepo.Update(ctx, id, userID, req, ruleType)
+}
+
+// Delete elimina una regla.
+func (s *RuleService) Delete(ctx context.Context, id, userID string) error {
+	return s.ruleRepo.Delete(ctx, id, userID)
+}
+
+// Categorize devuelve la primera regla activa que coincide con la transacción, o nil.
+func (s *RuleService) Categorize(ctx context.Context, userID, txType, description string, amount float64) (*models.CategorizationRule, error) {
+	m, err := s.matcher(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return m.match(txType, description, amount), nil
+}
+
+// Apply recategoriza las transacciones existentes (ingresos y gastos) según las reglas.
+// Con DryRun (por defecto) solo devuelve los cambios que se harían.
+func (s *RuleService) Apply(ctx context.Context, userID string, req models.ApplyRulesRequest) (*models.ApplyRulesResponse, error) {
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	m, err := s.matcher(ctx, userID, req.RuleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.RuleIDs) > 0 && len(m.rules) != len(req.RuleIDs) {
+		return nil, errors.New("alguna de las reglas no existe o está desactivada")
+	}
+
+	transactions, err := s.transactionRepo.GetAllForExport(ctx, userID, models.TransactionFilter{
+		DateFrom: req.DateFrom,
+		DateTo:   req.DateTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.GetAllByUser(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for _, cat := range categories {
+		names[cat.ID] = cat.Name
+	}
+
+	resp := &models.ApplyRulesResponse{DryRun: dryRun, Changes: []models.RuleChange{}}
+	for _, t := range transactions {
+		// Las divididas ya tienen una categoría por división: las reglas no las tocan
+		if t.Type == "transfer" || len(t.Splits) > 0 {
+			continue
+		}
+		resp.Checked++
+
+		rule := m.match(t.Type, t.Description, t.Amount)
+		if rule == nil || rule.CategoryID == t.CategoryID {
+			continue
+		}
+		resp.Changes = append(resp.Changes, models.RuleChange{
+			TransactionID:    t.ID,
+			Date:             t.DateStr,
+			Description:      t.Description,
+			Amount:           t.Amount,
+			FromCategoryID:   t.CategoryID,
+			FromCategoryName: t.CategoryName,
+			ToCategoryID:     rule.CategoryID,
+			ToCategoryName:   names[rule.CategoryID],
+			RuleID:           rule.ID,
+			RuleName:         rule.Name,
+		})
+	}
+
+	if dryRun || len(resp.Changes) == 0 {
+		return resp, nil
+	}
+
+	updated, err := s.ruleRepo.ApplyChanges(ctx, userID, resp.Changes)
+	if err != nil {
+		return nil, err
+	}
+	resp.Updated = updated
+	s.suggestions.Invalidate(userID)
+	return resp, nil
+}
+
+// ruleMatcher evalúa un conjunto de reglas ya cargadas (y con sus regex compiladas).
+// Se carga una vez por request: en un import se reutiliza para todas las filas.
+type ruleMatcher struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule    models.CategorizationRule
+	pattern string         // Normalizado, para "contains"
+	re      *regexp.Regexp // Para "regex"
+}
+
+// matcher carga las reglas activas del usuario. Si ruleIDs no está vacío, solo esas.
+func (s *RuleService) matcher(ctx context.Context, userID string, ruleIDs []string) (*ruleMatcher, error) {
+	rules, err := s.ruleRepo.GetAllByUser(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(ruleIDs))
+	for _, id := range ruleIDs {
+		wanted[id] = true
+	}
+
+	m := &ruleMatcher{}
+	for _, rule := range rules {
+		if len(wanted) > 0 && !wanted[rule.ID] {
+			continue
+		}
+		cr := compiledRule{rule: rule}
+		if rule.MatchType == "regex" {
+			// Se validó al guardar; si aun así falla, la regla se ignora
+			re, err := regexp.Compile("(?i)" + rule.Pattern)
+			if err != nil {
+				continue
+			}
+			cr.re = re
+		} else {
+			cr.pattern = textutil.Normalize(rule.Pattern)
+		}
+		m.rules = append(m.rules, cr)
+	}
+	return m, nil
+}
+
+// match devuelve la primera regla (por prioridad) que coincide, o nil.
+func (m *ruleMatcher) match(txType, description string, amount float64) *models.CategorizationRule {
+	normalized := textutil.Normalize(description)
+	for i := range m.rules {
+		cr := &m.rules[i]
+		rule := &cr.rule
+		if rule.Type != txType {
+			continue
+		}
+		if rule.MinAmount != nil && amount < *rule.MinAmount {
+			continue
+		}
+		if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+			continue
+		}
+		if cr.re != nil {
+			if !cr.re.MatchString(description) {
+				continue
+			}
+		} else if cr.pattern == "" || !strings.Contains(normalized, cr.pattern) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// validatePattern verifica que la expresión regular compile.
+func validatePattern(matchType, pattern string) error {
+	if matchType != "regex" {
+		if textutil.Normalize(pattern) == "" {
+			return errors.New("el patrón debe tener al menos una letra o número")
+		}
+		return nil
+	}
+	if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+		return fmt.Errorf("expresión regular inválida: %w", err)
+	}
+	return nil
+}
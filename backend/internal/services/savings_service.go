@@ -4,11 +4,19 @@ package services
 import (
 	"context"
 	"errors"
+	"math"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 	"expense-tracker-backend/internal/repository"
 )
 
+// goalWindowMonths es cuántos meses de historial se usan para estimar el aporte mensual.
+const goalWindowMonths = 6
+
+// ErrNoSavingsGoal se devuelve cuando se pide la meta de una cuenta que no tiene.
+var ErrNoSavingsGoal = errors.New("esta cuenta de ahorro no tiene una meta definida")
+
 type SavingsService struct {
 	savingsRepo *repository.SavingsRepository
 }
@@ -22,7 +30,11 @@ func (s *SavingsService) Create(ctx context.Context, userID string, req models.C
 	if req.Balance < 0 {
 		return nil, errors.New("el balance inicial no puede ser negativo")
 	}
-	return s.savingsRepo.Create(ctx, userID, req.Name, req.Balance, req.Color, req.Icon, req.Notes)
+	targetDate, err := parseTargetDate(req.TargetDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.savingsRepo.Create(ctx, userID, req.Name, req.Balance, req.Color, req.Icon, req.Notes, req.TargetAmount, targetDate)
 }
 
 // GetAll devuelve todas las cuentas de ahorro del usuario.
@@ -34,9 +46,37 @@ func (s *SavingsService) GetAll(ctx context.Context, userID string) ([]models.Sa
 	if accounts == nil {
 		accounts = []models.SavingsAccount{}
 	}
+
+	// Calcular el progreso de las cuentas que tienen meta
+	now := time.Now()
+	contributions, err := s.savingsRepo.GetContributions(ctx, userID, now.AddDate(0, -goalWindowMonths, 0))
+	if err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		accounts[i].Goal = computeGoal(&accounts[i], contributions[accounts[i].ID], now)
+	}
 	return accounts, nil
 }
 
+// GetGoal devuelve el progreso de la meta de una cuenta.
+func (s *SavingsService) GetGoal(ctx context.Context, id, userID string) (*models.SavingsGoal, error) {
+	acc, err := s.savingsRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if acc.TargetAmount == nil {
+		return nil, ErrNoSavingsGoal
+	}
+
+	now := time.Now()
+	contributions, err := s.savingsRepo.GetContributions(ctx, userID, now.AddDate(0, -goalWindowMonths, 0))
+	if err != nil {
+		return nil, err
+	}
+	return computeGoal(acc, contributions[acc.ID], now), nil
+}
+
 // Update actualiza una cuenta de ahorro.
 func (s *SavingsService) Update(ctx context.Context, id, userID string, req models.UpdateSavingsAccountRequest) (*models.SavingsAccount, error) {
 	// Primero verificar que exista y sea del usuario
@@ -50,9 +90,8 @@ func (s *SavingsService) Update(ctx context.Context, id, userID string, req mode
 	if req.Name != "" {
 		name = req.Name
 	}
-	balance := existing.Balance
-	if req.Balance != 0 || req.Balance == 0 {
-		balance = req.Balance
+	if req.Balance != nil && *req.Balance < 0 {
+		return nil, errors.New("el balance no puede ser negativo")
 	}
 	color := existing.Color
 	if req.Color != "" {
@@ -62,9 +101,34 @@ func (s *SavingsService) Update(ctx context.Context, id, userID string, req mode
 	if req.Icon != "" {
 		icon = req.Icon
 	}
-	notes := req.Notes
+	notes := existing.Notes
+	if req.Notes != nil {
+		notes = *req.Notes
+	}
+
+	// Meta: nil = conservar la actual, 0 / "" = quitarla
+	targetAmount := existing.TargetAmount
+	if req.TargetAmount != nil {
+		if *req.TargetAmount < 0 {
+			return nil, errors.New("el monto objetivo no puede ser negativo")
+		}
+		targetAmount = req.TargetAmount
+		if *req.TargetAmount == 0 {
+			targetAmount = nil
+		}
+	}
+	var targetDate *string
+	if existing.TargetDate != nil {
+		targetDate = &existing.TargetDateStr
+	}
+	if req.TargetDate != nil {
+		targetDate, err = parseTargetDate(*req.TargetDate)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return s.savingsRepo.Update(ctx, id, userID, name, balance, color, icon, notes)
+	return s.savingsRepo.Update(ctx, id, userID, name, req.Balance, color, icon, notes, targetAmount, targetDate)
 }
 
 // AdjustBalance deposita o retira dinero de una cuenta.
@@ -90,7 +154,7 @@ func (s *SavingsService) AdjustBalance(ctx context.Context, id, userID string, r
 		adjustAmount = -req.Amount
 	}
 
-	return s.savingsRepo.AdjustBalance(ctx, id, userID, adjustAmount)
+	return s.savingsRepo.AdjustBalance(ctx, id, userID, adjustAmount, req.Type, req.Note)
 }
 
 // Delete elimina una cuenta de ahorro.
@@ -102,3 +166,180 @@ func (s *SavingsService) Delete(ctx context.Context, id, userID string) error {
 func (s *SavingsService) GetTotal(ctx context.Context, userID string) (float64, error) {
 	return s.savingsRepo.GetTotalByUser(ctx, userID)
 }
+
+// GetMovements devuelve el historial de movimientos de una cuenta con paginación.
+func (s *SavingsService) GetMovements(ctx context.Context, filter models.SavingsMovementFilter) (*models.SavingsMovementListResponse, error) {
+	// Verificar que la cuenta exista y sea del usuario
+	if _, err := s.savingsRepo.GetByID(ctx, filter.AccountID, filter.UserID); err != nil {
+		return nil, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	movements, total, err := s.savingsRepo.GetMovements(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if movements == nil {
+		movements = []models.SavingsMovement{}
+	}
+
+	return &models.SavingsMovementListResponse{
+		Movements:  movements,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(filter.Limit))),
+	}, nil
+}
+
+// GetHistory devuelve la serie de saldos de una cuenta agrupada por día, semana o mes.
+// Los periodos sin movimientos se rellenan con el saldo del periodo anterior,
+// y la serie llega hasta el periodo actual para que la gráfica no se corte.
+func (s *SavingsService) GetHistory(ctx context.Context, id, userID, granularity string) (*models.SavingsHistoryResponse, error) {
+	if granularity == "" {
+		granularity = "month"
+	}
+	if granularity != "day" && granularity != "week" && granularity != "month" {
+		return nil, errors.New("granularity debe ser day, week o month")
+	}
+
+	if _, err := s.savingsRepo.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	points, err := s.savingsRepo.GetBalanceHistory(ctx, id, userID, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SavingsHistoryResponse{
+		AccountID:   id,
+		Granularity: granularity,
+		Points:      fillBalanceGaps(points, granularity, time.Now()),
+	}, nil
+}
+
+// fillBalanceGaps completa los periodos sin movimientos repitiendo el último saldo conocido.
+func fillBalanceGaps(points []models.BalancePoint, granularity string, now time.Time) []models.BalancePoint {
+	filled := []models.BalancePoint{}
+	if len(points) == 0 {
+		return filled
+	}
+
+	step := func(t time.Time) time.Time {
+		switch granularity {
+		case "day":
+			return t.AddDate(0, 0, 1)
+		case "week":
+			return t.AddDate(0, 0, 7)
+		default:
+			return t.AddDate(0, 1, 0)
+		}
+	}
+
+	current, _ := time.Parse("2006-01-02", points[0].Period)
+	end := truncatePeriod(now, granularity)
+	balance := points[0].Balance
+	next := 0
+
+	for !current.After(end) || next < len(points) {
+		period := current.Format("2006-01-02")
+		// Las fechas "2006-01-02" se pueden comparar como texto
+		for next < len(points) && points[next].Period <= period {
+			balance = points[next].Balance
+			next++
+		}
+		filled = append(filled, models.BalancePoint{Period: period, Balance: balance})
+		current = step(current)
+	}
+	return filled
+}
+
+// truncatePeriod devuelve el inicio del periodo que contiene a t (igual que date_trunc en PostgreSQL).
+func truncatePeriod(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case "day":
+		return day
+	case "week":
+		// date_trunc('week') empieza la semana el lunes
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// parseTargetDate valida la fecha de la meta. "" significa sin fecha (nil).
+func parseTargetDate(value string) (*string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return nil, errors.New("target_date debe tener formato YYYY-MM-DD")
+	}
+	return &value, nil
+}
+
+// computeGoal calcula progreso, aporte requerido y fecha estimada de la meta.
+// Devuelve nil si la cuenta no tiene monto objetivo.
+func computeGoal(acc *models.SavingsAccount, contribution models.SavingsContribution, now time.Time) *models.SavingsGoal {
+	if acc.TargetAmount == nil || *acc.TargetAmount <= 0 {
+		return nil
+	}
+	target := *acc.TargetAmount
+
+	goal := &models.SavingsGoal{
+		TargetAmount: target,
+		TargetDate:   acc.TargetDateStr,
+		Balance:      acc.Balance,
+		Remaining:    roundMoney(math.Max(0, target-acc.Balance)),
+	}
+	goal.ProgressPercent = math.Round(math.Min(100, acc.Balance/target*100)*100) / 100
+	goal.Completed = goal.Remaining == 0
+
+	// Aporte promedio: movimientos netos de la ventana dividido por los meses que cubre.
+	// Si la cuenta (o su historial) es más nueva que la ventana, se usa solo lo que existe.
+	windowStart := now.AddDate(0, -goalWindowMonths, 0)
+	if !contribution.FirstMovement.IsZero() && contribution.FirstMovement.After(windowStart) {
+		windowStart = contribution.FirstMovement
+	}
+	months := math.Max(1, monthsBetween(windowStart, now))
+	goal.AvgMonthlyContribution = roundMoney(contribution.Net / months)
+
+	if acc.TargetDate != nil && !goal.Completed {
+		monthsLeft := math.Max(1, monthsBetween(now, *acc.TargetDate))
+		required := roundMoney(goal.Remaining / monthsLeft)
+		onTrack := goal.AvgMonthlyContribution >= required
+		goal.RequiredMonthly = &required
+		goal.OnTrack = &onTrack
+	}
+
+	if goal.Completed {
+		goal.ProjectedCompletion = now.Format("2006-01-02")
+	} else if goal.AvgMonthlyContribution > 0 {
+		days := goal.Remaining / goal.AvgMonthlyContribution * daysPerMonth
+		goal.ProjectedCompletion = now.AddDate(0, 0, int(math.Ceil(days))).Format("2006-01-02")
+	}
+
+	return goal
+}
+
+// daysPerMonth es la duración promedio de un mes (365.25 / 12).
+const daysPerMonth = 30.4375
+
+// monthsBetween devuelve los meses (con decimales) entre dos fechas. Negativo si to < from.
+func monthsBetween(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysPerMonth
+}
+
+// roundMoney redondea a 2 decimales.
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,114 @@
+// Service de sugerencias de categoría — clasificador entrenado con el historial del usuario.
+package services
+
+import (
+	"context"
+	"time"
+
+	"expense-tracker-backend/internal/classifier"
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+const (
+	// trainingSampleLimit es cuántas transacciones recientes se usan para entrenar.
+	trainingSampleLimit = 5000
+	// modelMaxAge: los modelos se reentrenan completos pasado este tiempo, para recoger
+	// cambios hechos fuera de esta API (ej: el worker de recurrentes).
+	modelMaxAge = 6 * time.Hour
+	// trainingTimeout limita la consulta de entrenamiento.
+	trainingTimeout = 10 * time.Second
+)
+
+type SuggestionService struct {
+	store        *classifier.Store
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewSuggestionService(transactionRepo *repository.TransactionRepository, categoryRepo *repository.CategoryRepository) *SuggestionService {
+	load := func(userID string) ([]classifier.Sample, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), trainingTimeout)
+		defer cancel()
+
+		transactions, err := transactionRepo.GetTrainingSamples(ctx, userID, trainingSampleLimit)
+		if err != nil {
+			return nil, err
+		}
+		samples := make([]classifier.Sample, len(transactions))
+		for i, t := range transactions {
+			samples[i] = sampleOf(&t)
+		}
+		return samples, nil
+	}
+
+	return &SuggestionService{
+		store:        classifier.NewStore(load, modelMaxAge),
+		categoryRepo: categoryRepo,
+	}
+}
+
+// Suggest devuelve las categorías más probables para una descripción y monto.
+// txType ("income" o "expense", opcional) limita las sugerencias a ese tipo.
+func (s *SuggestionService) Suggest(ctx context.Context, userID, description string, amount float64, txType string, limit int) ([]models.CategorySuggestion, error) {
+	// Las archivadas no se sugieren
+	categories, err := s.categoryRepo.GetAllByUser(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Category, len(categories))
+	allowed := make(map[string]bool, len(categories))
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+		if txType == "" || cat.Type == txType {
+			allowed[cat.ID] = true
+		}
+	}
+
+	model, err := s.store.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []models.CategorySuggestion{}
+	for _, p := range model.Predict(description, amount, allowed, limit) {
+		cat := byID[p.CategoryID]
+		suggestions = append(suggestions, models.CategorySuggestion{
+			CategoryID:    cat.ID,
+			CategoryName:  cat.Name,
+			CategoryColor: cat.Color,
+			CategoryIcon:  cat.Icon,
+			Type:          cat.Type,
+			Confidence:    p.Confidence,
+		})
+	}
+	return suggestions, nil
+}
+
+// Learn agrega una transacción nueva al modelo del usuario.
+func (s *SuggestionService) Learn(userID string, t *models.Transaction) {
+	if t == nil || t.Type == "transfer" {
+		return
+	}
+	s.store.Add(userID, sampleOf(t))
+}
+
+// Forget quita del modelo una transacción borrada o con los datos viejos de una editada.
+func (s *SuggestionService) Forget(userID string, t *models.Transaction) {
+	if t == nil || t.Type == "transfer" {
+		return
+	}
+	s.store.Remove(userID, sampleOf(t))
+}
+
+// Invalidate descarta el modelo del usuario tras cambios masivos (imports, reglas aplicadas).
+func (s *SuggestionService) Invalidate(userID string) {
+	s.store.Invalidate(userID)
+}
+
+func sampleOf(t *models.Transaction) classifier.Sample {
+	return classifier.Sample{
+		CategoryID:  t.CategoryID,
+		Description: t.Description,
+		Amount:      t.Amount,
+	}
+}
@@ -0,0 +1,53 @@
+// Service de etiquetas.
+package services
+
+import (
+	"context"
+	"errors"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+type TagService struct {
+	tagRepo *repository.TagRepository
+}
+
+func NewTagService(tagRepo *repository.TagRepository) *TagService {
+	return &TagService{tagRepo: tagRepo}
+}
+
+// GetAll devuelve las etiquetas del usuario.
+func (s *TagService) GetAll(ctx context.Context, userID string) ([]models.Tag, error) {
+	tags, err := s.tagRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	return tags, nil
+}
+
+// Create crea una etiqueta con el nombre normalizado ("Viaje Cartagena" → "viaje-cartagena").
+func (s *TagService) Create(ctx context.Context, userID string, req models.CreateTagRequest) (*models.Tag, error) {
+	name := models.NormalizeTagName(req.Name)
+	if name == "" {
+		return nil, errors.New("el nombre de la etiqueta no puede estar vacío")
+	}
+	return s.tagRepo.Create(ctx, userID, name, req.Color)
+}
+
+// Update renombra una etiqueta o cambia su color.
+func (s *TagService) Update(ctx context.Context, id, userID string, req models.UpdateTagRequest) (*models.Tag, error) {
+	name := models.NormalizeTagName(req.Name)
+	if name == "" && req.Color == "" {
+		return nil, errors.New("no se proporcionaron campos para actualizar")
+	}
+	return s.tagRepo.Update(ctx, id, userID, name, req.Color)
+}
+
+// Delete elimina una etiqueta.
+func (s *TagService) Delete(ctx context.Context, id, userID string) error {
+	return s.tagRepo.Delete(ctx, id, userID)
+}
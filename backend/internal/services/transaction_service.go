@@ -2,23 +2,60 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"math"
 	"strings"
+	"time"
 
 	"expense-tracker-backend/internal/models"
 	"expense-tracker-backend/internal/repository"
+	"expense-tracker-backend/internal/textutil"
 )
 
+// ErrCategoryRequired se devuelve al crear sin category_id cuando ninguna regla aplica.
+var ErrCategoryRequired = errors.New("indica una categoría: ninguna regla de categorización coincide con esta transacción")
+
+// ErrInvalidSplits se devuelve cuando las divisiones no suman el monto de la transacción.
+var ErrInvalidSplits = errors.New("divisiones inválidas")
+
+// ErrSplitsOutdated se devuelve al cambiar el monto o el tipo de una transacción dividida
+// sin enviar las divisiones nuevas.
+var ErrSplitsOutdated = errors.New("la transacción está dividida: envía también las divisiones con el nuevo monto o tipo")
+
+// ErrInvalidBulk se devuelve cuando una operación masiva no trae lo que su acción necesita.
+var ErrInvalidBulk = errors.New("operación masiva inválida")
+
+// maxBulkTransactions limita cuántas transacciones puede tocar una operación masiva.
+const maxBulkTransactions = 1000
+
 type TransactionService struct {
 	transactionRepo *repository.TransactionRepository
+	duplicateRepo   *repository.DuplicateRepository
+	ruleService     *RuleService
+	suggestions     *SuggestionService
 }
 
-func NewTransactionService(transactionRepo *repository.TransactionRepository) *TransactionService {
-	return &TransactionService{transactionRepo: transactionRepo}
+func NewTransactionService(
+	transactionRepo *repository.TransactionRepository,
+	duplicateRepo *repository.DuplicateRepository,
+	ruleService *RuleService,
+	suggestions *SuggestionService,
+) *TransactionService {
+	return &TransactionService{
+		transactionRepo: transactionRepo,
+		duplicateRepo:   duplicateRepo,
+		ruleService:     ruleService,
+		suggestions:     suggestions,
+	}
 }
 
+// ErrCursorSort se devuelve al pedir paginación por cursor con un orden distinto a la fecha.
+var ErrCursorSort = errors.New("la paginación por cursor solo admite el orden por fecha (sort=date) y sin búsqueda por relevancia")
+
 // GetFiltered devuelve transacciones paginadas y filtradas.
+// Con filter.Keyset pagina por cursor: next_cursor apunta a la última transacción devuelta.
 func (s *TransactionService) GetFiltered(ctx context.Context, filter models.TransactionFilter) (*models.TransactionListResponse, error) {
 	// Validar y ajustar paginación
 	if filter.Page < 1 {
@@ -27,8 +64,12 @@ func (s *TransactionService) GetFiltered(ctx context.Context, filter models.Tran
 	if filter.Limit < 1 || filter.Limit > 100 {
 		filter.Limit = 20
 	}
+	byRelevance := filter.Sort == "" && filter.Query != ""
+	if filter.Keyset && (byRelevance || (filter.Sort != "" && filter.Sort != "date")) {
+		return nil, ErrCursorSort
+	}
 
-	transactions, total, err := s.transactionRepo.GetFiltered(ctx, filter)
+	transactions, hasMore, err := s.transactionRepo.GetFiltered(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -37,30 +78,215 @@ func (s *TransactionService) GetFiltered(ctx context.Context, filter models.Tran
 		transactions = []models.Transaction{}
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
-
-	return &models.TransactionListResponse{
+	response := &models.TransactionListResponse{
 		Transactions: transactions,
-		Total:        total,
-		Page:         filter.Page,
 		Limit:        filter.Limit,
-		TotalPages:   totalPages,
-	}, nil
+		HasMore:      hasMore,
+	}
+	if filter.Keyset {
+		if hasMore {
+			response.NextCursor = models.CursorOf(&transactions[len(transactions)-1]).Encode()
+		}
+	} else {
+		response.Page = filter.Page
+	}
+
+	if !filter.SkipTotal {
+		total, err := s.transactionRepo.CountFiltered(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
+		response.Total = &total
+		response.TotalPages = &totalPages
+	}
+
+	return response, nil
 }
 
-// Create crea una nueva transacción.
+// Create crea una nueva transacción y adjunta las existentes que parecen duplicadas.
+// Sin category_id, la categoría la decide la primera regla que coincida
+// (o la división más grande, si la transacción viene dividida).
+// La detección de duplicados es solo un aviso: si falla, la transacción se devuelve igual.
 func (s *TransactionService) Create(ctx context.Context, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
-	return s.transactionRepo.Create(ctx, userID, req)
+	if len(req.Splits) > 0 {
+		if err := validateSplits(req.Amount, req.Splits); err != nil {
+			return nil, err
+		}
+		if req.CategoryID == "" {
+			req.CategoryID = largestSplit(req.Splits).CategoryID
+		}
+	}
+
+	if req.CategoryID == "" {
+		rule, err := s.ruleService.Categorize(ctx, userID, req.Type, req.Description, req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			return nil, ErrCategoryRequired
+		}
+		req.CategoryID = rule.CategoryID
+	}
+
+	t, err := s.transactionRepo.Create(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	s.suggestions.Learn(userID, t)
+
+	window := DefaultDuplicateWindowDays
+	existing, err := s.duplicateRepo.GetInRange(ctx, userID,
+		t.Date.AddDate(0, 0, -window).Format("2006-01-02"),
+		t.Date.AddDate(0, 0, window).Format("2006-01-02"),
+	)
+	if err != nil {
+		log.Printf("Error buscando duplicados de %s: %v", t.ID, err)
+		return t, nil
+	}
+	t.PossibleDuplicates = matchDuplicates(duplicateProbe{
+		ID:          t.ID,
+		Type:        t.Type,
+		Amount:      t.Amount,
+		Date:        t.Date,
+		Description: t.Description,
+	}, existing, window)
+	return t, nil
 }
 
-// Update actualiza una transacción existente.
+// GetDuplicates devuelve los pares de transacciones que probablemente están repetidas,
+// sin los que el usuario ya descartó. windowDays fuera de rango usa el valor por defecto.
+func (s *TransactionService) GetDuplicates(ctx context.Context, userID string, windowDays int, dateFrom, dateTo string) ([]models.DuplicatePair, error) {
+	if windowDays < 0 || windowDays > MaxDuplicateWindowDays {
+		windowDays = DefaultDuplicateWindowDays
+	}
+
+	candidates, err := s.duplicateRepo.GetCandidatePairs(ctx, userID, windowDays, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []models.DuplicatePair{}
+	for _, p := range candidates {
+		similarity := textutil.Similarity(p.Transaction.Description, p.Duplicate.Description)
+		if similarity < duplicateSimilarity {
+			continue
+		}
+		p.Similarity = math.Round(similarity*100) / 100
+		p.DaysApart = daysApart(p.Transaction.Date, p.Duplicate.Date)
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
+
+// DismissDuplicate marca un par como "no es duplicado" para que no se vuelva a reportar.
+func (s *TransactionService) DismissDuplicate(ctx context.Context, userID string, req models.DismissDuplicateRequest) error {
+	return s.duplicateRepo.Dismiss(ctx, userID, req.TransactionID, req.DuplicateID)
+}
+
+// Update actualiza una transacción existente y reentrena el clasificador con los datos nuevos.
+// Si está dividida, cambiar el monto o el tipo exige enviar también las divisiones nuevas.
 func (s *TransactionService) Update(ctx context.Context, id, userID string, req models.UpdateTransactionRequest) (*models.Transaction, error) {
-	return s.transactionRepo.Update(ctx, id, userID, req)
+	old, err := s.transactionRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Las divisiones deben seguir sumando el monto final
+	amount := old.Amount
+	if req.Amount > 0 {
+		amount = req.Amount
+	}
+	switch {
+	case len(req.Splits) > 0:
+		if err := validateSplits(amount, req.Splits); err != nil {
+			return nil, err
+		}
+	case req.Splits == nil && len(old.Splits) > 0:
+		if amount != old.Amount || (req.Type != "" && req.Type != old.Type) {
+			return nil, ErrSplitsOutdated
+		}
+	}
+
+	t, err := s.transactionRepo.Update(ctx, id, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	s.suggestions.Forget(userID, old)
+	s.suggestions.Learn(userID, t)
+	return t, nil
 }
 
 // Delete elimina una transacción.
 func (s *TransactionService) Delete(ctx context.Context, id, userID string) error {
-	return s.transactionRepo.Delete(ctx, id, userID)
+	old, err := s.transactionRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return fmt.Errorf("transacción no encontrada o no tienes permiso")
+	}
+
+	if err := s.transactionRepo.Delete(ctx, id, userID); err != nil {
+		return err
+	}
+	s.suggestions.Forget(userID, old)
+	return nil
+}
+
+// Bulk aplica una misma acción a varias transacciones (por IDs o por filtro) y devuelve
+// el resultado de cada una. Ver TransactionRepository.ApplyBulk.
+func (s *TransactionService) Bulk(ctx context.Context, userID string, req models.BulkTransactionRequest) (*models.BulkResult, error) {
+	if (len(req.IDs) > 0) == (req.Filter != nil) {
+		return nil, fmt.Errorf("%w: envía ids o filter (uno de los dos)", ErrInvalidBulk)
+	}
+
+	switch req.Action {
+	case models.BulkSetCategory:
+		if req.CategoryID == "" {
+			return nil, fmt.Errorf("%w: set_category requiere category_id", ErrInvalidBulk)
+		}
+	case models.BulkAddTag:
+		req.Tag = models.NormalizeTagName(req.Tag)
+		if req.Tag == "" {
+			return nil, fmt.Errorf("%w: add_tag requiere tag", ErrInvalidBulk)
+		}
+	case models.BulkSetDate:
+		if req.Date == "" {
+			return nil, fmt.Errorf("%w: set_date requiere date", ErrInvalidBulk)
+		}
+	case models.BulkSetCurrency:
+		if req.Currency == "" {
+			return nil, fmt.Errorf("%w: set_currency requiere currency", ErrInvalidBulk)
+		}
+		req.Currency = strings.ToUpper(req.Currency)
+	}
+
+	var filter *models.TransactionFilter
+	if req.Filter != nil {
+		if err := normalizeViewFilters(req.Filter); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBulk, err)
+		}
+		f := ViewTransactionFilter(userID, *req.Filter, time.Now())
+		filter = &f
+	}
+
+	// Un mismo ID repetido se procesa una sola vez
+	seen := make(map[string]bool, len(req.IDs))
+	ids := make([]string, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	result, err := s.transactionRepo.ApplyBulk(ctx, userID, ids, filter, req, maxBulkTransactions)
+	if err != nil {
+		return nil, err
+	}
+	// Borrar o recategorizar en bloque cambia mucho los datos del clasificador
+	if result.Succeeded > 0 && (req.Action == models.BulkDelete || req.Action == models.BulkSetCategory) {
+		s.suggestions.Invalidate(userID)
+	}
+	return result, nil
 }
 
 // ExportCSV genera el contenido CSV de todas las transacciones del usuario.
@@ -76,8 +302,11 @@ func (s *TransactionService) ExportCSV(ctx context.Context, userID string, filte
 
 	for _, t := range transactions {
 		tipo := "Gasto"
-		if t.Type == "income" {
+		switch t.Type {
+		case "income":
 			tipo = "Ingreso"
+		case "transfer":
+			tipo = "Transferencia"
 		}
 		// Escapar comillas en la descripción
 		desc := strings.ReplaceAll(t.Description, "\"", "\"\"")
@@ -88,3 +317,27 @@ func (s *TransactionService) ExportCSV(ctx context.Context, userID string, filte
 
 	return sb.String(), nil
 }
+
+// validateSplits verifica que los montos de las divisiones sumen exactamente el total
+// (comparando en centavos para evitar errores de redondeo).
+func validateSplits(amount float64, splits []models.SplitRequest) error {
+	var sum int64
+	for _, split := range splits {
+		sum += int64(math.Round(split.Amount * 100))
+	}
+	if sum != int64(math.Round(amount*100)) {
+		return fmt.Errorf("%w: suman %.2f pero la transacción es de %.2f", ErrInvalidSplits, float64(sum)/100, amount)
+	}
+	return nil
+}
+
+// largestSplit devuelve la división de mayor monto (la primera si hay empate).
+func largestSplit(splits []models.SplitRequest) models.SplitRequest {
+	largest := splits[0]
+	for _, split := range splits[1:] {
+		if split.Amount > largest.Amount {
+			largest = split
+		}
+	}
+	return largest
+}
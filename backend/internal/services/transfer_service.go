@@ -0,0 +1,56 @@
+// Service de transferencias — valida los lados antes de mover dinero.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+type TransferService struct {
+	transferRepo *repository.TransferRepository
+	savingsRepo  *repository.SavingsRepository
+}
+
+func NewTransferService(transferRepo *repository.TransferRepository, savingsRepo *repository.SavingsRepository) *TransferService {
+	return &TransferService{transferRepo: transferRepo, savingsRepo: savingsRepo}
+}
+
+// Create registra una transferencia entre el flujo principal y/o cuentas de ahorro.
+func (s *TransferService) Create(ctx context.Context, userID string, req models.CreateTransferRequest) (*models.Transaction, error) {
+	if req.FromAccountID == "" && req.ToAccountID == "" {
+		return nil, errors.New("al menos uno de los lados debe ser una cuenta de ahorro")
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return nil, errors.New("el origen y el destino no pueden ser la misma cuenta")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return nil, errors.New("la fecha debe tener formato YYYY-MM-DD")
+	}
+
+	// Verificar que las cuentas existan y sean del usuario
+	if req.FromAccountID != "" {
+		from, err := s.savingsRepo.GetByID(ctx, req.FromAccountID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if from.Balance < req.Amount {
+			return nil, repository.ErrInsufficientFunds
+		}
+	}
+	if req.ToAccountID != "" {
+		if _, err := s.savingsRepo.GetByID(ctx, req.ToAccountID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.transferRepo.Create(ctx, userID, req)
+}
+
+// Delete revierte una transferencia y devuelve el dinero a su origen.
+func (s *TransferService) Delete(ctx context.Context, id, userID string) error {
+	return s.transferRepo.Delete(ctx, id, userID)
+}
@@ -0,0 +1,68 @@
+// Service de la papelera — lo borrado se puede restaurar durante el periodo de
+// retención; después el worker de la papelera lo elimina definitivamente.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+// ErrInvalidTrashType se devuelve cuando :type no es uno de models.TrashTypes.
+var ErrInvalidTrashType = errors.New("tipo inválido: usa transaction, category, savings_account o budget")
+
+type TrashService struct {
+	trashRepo   *repository.TrashRepository
+	suggestions *SuggestionService
+	retention   time.Duration
+}
+
+// NewTrashService crea el service. retention es cuánto tiempo se conserva lo borrado.
+func NewTrashService(trashRepo *repository.TrashRepository, suggestions *SuggestionService, retention time.Duration) *TrashService {
+	return &TrashService{trashRepo: trashRepo, suggestions: suggestions, retention: retention}
+}
+
+// GetAll devuelve lo que el usuario tiene en la papelera con la fecha en que se purgará.
+func (s *TrashService) GetAll(ctx context.Context, userID string) ([]models.TrashItem, error) {
+	items, err := s.trashRepo.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.TrashItem{}
+	}
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return items, nil
+}
+
+// Restore saca un elemento de la papelera. Si es una transacción, el clasificador
+// se reentrena para volver a tenerla en cuenta.
+func (s *TrashService) Restore(ctx context.Context, userID, itemType, id string) error {
+	valid := false
+	for _, t := range models.TrashTypes {
+		if t == itemType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrInvalidTrashType
+	}
+	if err := s.trashRepo.Restore(ctx, userID, itemType, id); err != nil {
+		return err
+	}
+	if itemType == models.TrashTransaction {
+		s.suggestions.Invalidate(userID)
+	}
+	return nil
+}
+
+// PurgeExpired elimina definitivamente lo que lleva en la papelera más que la retención.
+func (s *TrashService) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	return s.trashRepo.Purge(ctx, now.Add(-s.retention))
+}
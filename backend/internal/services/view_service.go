@@ -0,0 +1,145 @@
+// Service de vistas guardadas — validación y evaluación de filtros con fechas relativas.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"expense-tracker-backend/internal/models"
+	"expense-tracker-backend/internal/repository"
+)
+
+type ViewService struct {
+	viewRepo *repository.ViewRepository
+}
+
+func NewViewService(viewRepo *repository.ViewRepository) *ViewService {
+	return &ViewService{viewRepo: viewRepo}
+}
+
+// GetAll devuelve las vistas del usuario.
+func (s *ViewService) GetAll(ctx context.Context, userID string) ([]models.SavedView, error) {
+	views, err := s.viewRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if views == nil {
+		views = []models.SavedView{}
+	}
+	return views, nil
+}
+
+// GetByID devuelve una vista del usuario.
+func (s *ViewService) GetByID(ctx context.Context, id, userID string) (*models.SavedView, error) {
+	return s.viewRepo.GetByID(ctx, id, userID)
+}
+
+// Create valida y guarda una vista.
+func (s *ViewService) Create(ctx context.Context, userID string, req models.CreateViewRequest) (*models.SavedView, error) {
+	if err := normalizeViewFilters(&req.Filters); err != nil {
+		return nil, err
+	}
+	return s.viewRepo.Create(ctx, userID, req)
+}
+
+// Update renombra una vista o reemplaza sus filtros.
+func (s *ViewService) Update(ctx context.Context, id, userID string, req models.UpdateViewRequest) (*models.SavedView, error) {
+	if req.Name == "" && req.Filters == nil {
+		return nil, errors.New("no se proporcionaron campos para actualizar")
+	}
+	if req.Filters != nil {
+		if err := normalizeViewFilters(req.Filters); err != nil {
+			return nil, err
+		}
+	}
+	return s.viewRepo.Update(ctx, id, userID, req)
+}
+
+// Delete elimina una vista.
+func (s *ViewService) Delete(ctx context.Context, id, userID string) error {
+	return s.viewRepo.Delete(ctx, id, userID)
+}
+
+// Filter devuelve el filtro de transacciones de una vista, con las fechas relativas
+// calculadas a partir de hoy.
+func (s *ViewService) Filter(ctx context.Context, id, userID string) (*models.TransactionFilter, error) {
+	view, err := s.viewRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter := ViewTransactionFilter(userID, view.Filters, time.Now())
+	return &filter, nil
+}
+
+// ViewTransactionFilter convierte los filtros guardados en un TransactionFilter para el día now.
+func ViewTransactionFilter(userID string, f models.ViewFilters, now time.Time) models.TransactionFilter {
+	filter := models.TransactionFilter{
+		UserID:             userID,
+		Type:               f.Type,
+		CategoryIDs:        f.CategoryIDs,
+		ExcludeCategoryIDs: f.ExcludeCategoryIDs,
+		MinAmount:          f.MinAmount,
+		MaxAmount:          f.MaxAmount,
+		Currency:           f.Currency,
+		DateFrom:           f.DateFrom,
+		DateTo:             f.DateTo,
+		Tags:               f.Tags,
+		Query:              f.Query,
+		Sort:               f.Sort,
+		Order:              f.Order,
+	}
+	if f.DateRange != "" {
+		from, to := resolveDateRange(f.DateRange, now)
+		filter.DateFrom = from.Format("2006-01-02")
+		filter.DateTo = to.Format("2006-01-02")
+	}
+	return filter
+}
+
+// resolveDateRange calcula las fechas (inclusive) de un rango relativo.
+// Los "last_N_days" incluyen hoy.
+func resolveDateRange(name string, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := today.AddDate(0, 0, 1-today.Day())
+	quarterStart := time.Date(today.Year(), ((today.Month()-1)/3)*3+1, 1, 0, 0, 0, 0, today.Location())
+	yearStart := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+
+	switch name {
+	case "last_month":
+		return monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1)
+	case "this_quarter":
+		return quarterStart, quarterStart.AddDate(0, 3, -1)
+	case "last_quarter":
+		return quarterStart.AddDate(0, -3, 0), quarterStart.AddDate(0, 0, -1)
+	case "this_year":
+		return yearStart, yearStart.AddDate(1, 0, -1)
+	case "last_year":
+		return yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1)
+	case "year_to_date":
+		return yearStart, today
+	case "last_7_days":
+		return today.AddDate(0, 0, -6), today
+	case "last_30_days":
+		return today.AddDate(0, 0, -29), today
+	case "last_90_days":
+		return today.AddDate(0, 0, -89), today
+	default: // this_month
+		return monthStart, monthStart.AddDate(0, 1, -1)
+	}
+}
+
+// normalizeViewFilters normaliza las etiquetas y valida los rangos de los filtros.
+func normalizeViewFilters(f *models.ViewFilters) error {
+	f.Tags = models.NormalizeTagNames(f.Tags)
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return errors.New("min_amount no puede ser mayor que max_amount")
+	}
+	if f.DateRange != "" {
+		f.DateFrom, f.DateTo = "", ""
+	}
+	if f.DateFrom != "" && f.DateTo != "" && f.DateFrom > f.DateTo {
+		return errors.New("date_from no puede ser posterior a date_to")
+	}
+	return nil
+}
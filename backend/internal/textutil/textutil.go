@@ -0,0 +1,111 @@
+// Package textutil tiene utilidades para comparar textos escritos a mano o
+// exportados por bancos: sin tildes, sin mayúsculas y sin signos de puntuación.
+package textutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accentReplacer quita las tildes y la eñe para comparar textos.
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// Normalize deja el texto en minúsculas, sin tildes y con las palabras separadas
+// por un solo espacio. Los signos de puntuación se tratan como espacios.
+// Ej: "  Almacén ÉXITO - Calle 80 " → "almacen exito calle 80"
+func Normalize(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(s))
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Similarity devuelve qué tan parecidos son dos textos, de 0 (nada) a 1 (iguales),
+// después de normalizarlos. Se toma el mayor entre:
+//   - la distancia de Levenshtein relativa al texto más largo, y
+//   - la fracción de palabras del texto más corto que aparecen en el más largo
+//     ("Exito" vs "COMPRA POS EXITO CALLE 80" → 1).
+//
+// Dos textos vacíos se consideran iguales; uno vacío y otro no, distintos.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" {
+		return 0
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	edit := 1 - float64(Levenshtein(a, b))/float64(longest)
+
+	if words := wordOverlap(a, b); words > edit {
+		return words
+	}
+	return edit
+}
+
+// Levenshtein calcula la cantidad mínima de inserciones, borrados o cambios de
+// un carácter para convertir a en b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// wordOverlap es la fracción de palabras del texto más corto presentes en el otro.
+// Recibe textos ya normalizados.
+func wordOverlap(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	set := make(map[string]bool, len(wb))
+	for _, w := range wb {
+		set[w] = true
+	}
+	found := 0
+	for _, w := range wa {
+		if set[w] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(wa))
+}
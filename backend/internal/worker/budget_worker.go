@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"expense-tracker-backend/internal/services"
+)
+
+// BudgetWorker crea al comenzar cada semana, mes, trimestre o año los presupuestos
+// copiados del periodo anterior, para los usuarios que activaron auto_copy_budgets.
+type BudgetWorker struct {
+	budgetService *services.BudgetService
+	interval      time.Duration
+}
+
+// NewBudgetWorker crea el worker. interval es cada cuánto revisa si hay periodos por copiar.
+func NewBudgetWorker(budgetService *services.BudgetService, interval time.Duration) *BudgetWorker {
+	return &BudgetWorker{budgetService: budgetService, interval: interval}
+}
+
+// Start corre una pasada inmediata y luego una cada intervalo, hasta que ctx se cancele.
+// Se debe llamar en una goroutine: go w.Start(ctx)
+func (w *BudgetWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *BudgetWorker) runOnce(ctx context.Context) {
+	created, err := w.budgetService.AutoCopy(ctx, time.Now())
+	if err != nil {
+		log.Printf("Worker presupuestos: error: %v", err)
+		return
+	}
+	if created > 0 {
+		log.Printf("Worker presupuestos: %d presupuestos copiados del periodo anterior", created)
+	}
+}
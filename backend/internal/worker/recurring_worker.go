@@ -0,0 +1,53 @@
+// Package worker contiene tareas en segundo plano que corren junto al servidor HTTP.
+// Cada worker hace una pasada al arrancar y luego se repite cada cierto intervalo.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"expense-tracker-backend/internal/services"
+)
+
+// RecurringWorker materializa las transacciones recurrentes pendientes.
+type RecurringWorker struct {
+	recurringService *services.RecurringService
+	interval         time.Duration
+}
+
+// NewRecurringWorker crea el worker. interval es cada cuánto revisa si hay ocurrencias pendientes.
+func NewRecurringWorker(recurringService *services.RecurringService, interval time.Duration) *RecurringWorker {
+	return &RecurringWorker{recurringService: recurringService, interval: interval}
+}
+
+// Start corre una pasada inmediata y luego una cada intervalo, hasta que ctx se cancele.
+// Se debe llamar en una goroutine: go w.Start(ctx)
+func (w *RecurringWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *RecurringWorker) runOnce(ctx context.Context) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	created, err := w.recurringService.MaterializeDue(ctx, today)
+	if err != nil {
+		log.Printf("Worker recurrentes: error: %v", err)
+		return
+	}
+	if created > 0 {
+		log.Printf("Worker recurrentes: %d transacciones creadas", created)
+	}
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"expense-tracker-backend/internal/services"
+)
+
+// TrashWorker elimina definitivamente lo que pasó el periodo de retención en la papelera.
+type TrashWorker struct {
+	trashService *services.TrashService
+	interval     time.Duration
+}
+
+// NewTrashWorker crea el worker. interval es cada cuánto revisa la papelera.
+func NewTrashWorker(trashService *services.TrashService, interval time.Duration) *TrashWorker {
+	return &TrashWorker{trashService: trashService, interval: interval}
+}
+
+// Start corre una pasada inmediata y luego una cada intervalo, hasta que ctx se cancele.
+// Se debe llamar en una goroutine: go w.Start(ctx)
+func (w *TrashWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *TrashWorker) runOnce(ctx context.Context) {
+	purged, err := w.trashService.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Worker papelera: error: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Worker papelera: %d elementos eliminados definitivamente", purged)
+	}
+}
@@ -0,0 +1,37 @@
+-- ============================================
+-- Migración 009: Transacciones recurrentes
+-- Plantillas (arriendo, salario, Netflix...) que el worker convierte
+-- en transacciones reales cuando llega su fecha.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('income', 'expense')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL DEFAULT 'COP',
+    -- Frecuencia: daily, weekly, monthly (en day_of_month) o yearly
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    day_of_month INTEGER CHECK (day_of_month BETWEEN 1 AND 31),
+    start_date DATE NOT NULL,
+    end_date DATE,
+    -- Próxima fecha pendiente de materializar. El worker la avanza después de crear cada ocurrencia.
+    next_run_date DATE NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_user_id ON recurring_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_due ON recurring_transactions(next_run_date) WHERE active;
+
+-- Cada transacción generada apunta a su plantilla
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS recurring_id UUID REFERENCES recurring_transactions(id) ON DELETE SET NULL;
+
+-- Una plantilla solo puede generar una transacción por fecha.
+-- Esto hace que el worker sea idempotente aunque el servidor se reinicie a mitad de camino.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_recurring_date
+    ON transactions(recurring_id, date) WHERE recurring_id IS NOT NULL;
@@ -0,0 +1,36 @@
+-- ============================================
+-- Migración 010: Transferencias entre ahorros y flujo principal
+-- Una transferencia es una fila de transactions con type = 'transfer'.
+-- No tiene categoría y no cuenta como ingreso ni gasto en los reportes.
+-- ============================================
+
+-- Las transferencias no tienen categoría
+ALTER TABLE transactions ALTER COLUMN category_id DROP NOT NULL;
+
+-- Cuentas de origen y destino (NULL = flujo principal)
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS from_account_id UUID REFERENCES savings_accounts(id) ON DELETE SET NULL;
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS to_account_id UUID REFERENCES savings_accounts(id) ON DELETE SET NULL;
+
+-- Dirección de la transferencia. Se guarda explícita porque si se borra una cuenta
+-- de ahorro las columnas de arriba quedan en NULL y ya no se podría deducir.
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS transfer_kind VARCHAR(20)
+    CHECK (transfer_kind IN ('to_savings', 'from_savings', 'between_savings'));
+
+-- Ampliar los tipos permitidos y exigir categoría solo a ingresos/gastos.
+-- Se hace dentro de un DO para no revalidar la tabla en cada arranque.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'transactions_type_transfer_check') THEN
+        ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+        ALTER TABLE transactions ADD CONSTRAINT transactions_type_transfer_check
+            CHECK (type IN ('income', 'expense', 'transfer'));
+        ALTER TABLE transactions ADD CONSTRAINT transactions_category_required_check
+            CHECK (type = 'transfer' OR category_id IS NOT NULL);
+        ALTER TABLE transactions ADD CONSTRAINT transactions_transfer_kind_check
+            CHECK ((type = 'transfer') = (transfer_kind IS NOT NULL));
+    END IF;
+END $$;
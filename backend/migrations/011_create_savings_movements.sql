@@ -0,0 +1,42 @@
+-- ============================================
+-- Migración 011: Libro de movimientos de cuentas de ahorro
+-- Cada depósito, retiro, corrección manual o transferencia queda como una fila
+-- inmutable con el saldo resultante. Permite ver el historial y graficar el crecimiento.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS savings_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES savings_accounts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN (
+        'initial', 'deposit', 'withdraw', 'adjustment',
+        'transfer_in', 'transfer_out', 'transfer_reversal'
+    )),
+    amount DECIMAL(15, 2) NOT NULL,        -- Con signo: positivo entra, negativo sale
+    balance_after DECIMAL(15, 2) NOT NULL, -- Saldo de la cuenta después del movimiento
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    -- Transferencia que originó el movimiento (sin FK: el movimiento sobrevive aunque se revierta)
+    transaction_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_savings_movements_account ON savings_movements(account_id, created_at);
+
+-- Los movimientos son inmutables: no se pueden editar.
+-- (Sí se borran en cascada si se elimina la cuenta.)
+CREATE OR REPLACE FUNCTION savings_movements_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'los movimientos de ahorro no se pueden modificar';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_savings_movements_immutable ON savings_movements;
+CREATE TRIGGER trg_savings_movements_immutable
+    BEFORE UPDATE ON savings_movements
+    FOR EACH ROW EXECUTE FUNCTION savings_movements_immutable();
+
+-- Cuentas que ya existían: registrar su saldo actual como punto de partida del historial
+INSERT INTO savings_movements (user_id, account_id, type, amount, balance_after, note)
+SELECT sa.user_id, sa.id, 'initial', sa.balance, sa.balance, 'Saldo al activar el historial'
+FROM savings_accounts sa
+WHERE NOT EXISTS (SELECT 1 FROM savings_movements sm WHERE sm.account_id = sa.id);
@@ -0,0 +1,11 @@
+-- ============================================
+-- Migración 012: Metas de ahorro
+-- Una cuenta puede tener un monto objetivo y una fecha límite opcionales.
+-- Ejemplo: "Viaje a Cartagena: $3.000.000 antes del 2026-12-01"
+-- ============================================
+
+ALTER TABLE savings_accounts
+ADD COLUMN IF NOT EXISTS target_amount DECIMAL(15, 2) CHECK (target_amount > 0);
+
+ALTER TABLE savings_accounts
+ADD COLUMN IF NOT EXISTS target_date DATE;
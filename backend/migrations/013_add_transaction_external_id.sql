@@ -0,0 +1,13 @@
+-- ============================================
+-- Migración 013: ID externo de transacciones importadas
+-- Los extractos OFX traen un FITID único por movimiento. Se guarda para que
+-- reimportar el mismo extracto no duplique transacciones.
+-- ============================================
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+-- Un mismo ID externo solo puede existir una vez por usuario
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_user_external_id
+ON transactions(user_id, external_id)
+WHERE external_id IS NOT NULL;
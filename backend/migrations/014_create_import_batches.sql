@@ -0,0 +1,23 @@
+-- ============================================
+-- Migración 014: Lotes de importación
+-- Cada import confirmado queda registrado como un lote. Las transacciones que
+-- creó guardan el ID del lote para poder deshacer el import completo.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS import_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('csv', 'ofx')),
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    row_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_batches_user ON import_batches(user_id, created_at DESC);
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS import_batch_id UUID REFERENCES import_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch
+ON transactions(import_batch_id)
+WHERE import_batch_id IS NOT NULL;
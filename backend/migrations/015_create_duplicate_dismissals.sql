@@ -0,0 +1,20 @@
+-- ============================================
+-- Migración 015: Posibles duplicados descartados
+-- Cuando el usuario marca dos transacciones como "no son duplicado",
+-- el par se guarda aquí para no volver a sugerirlo.
+-- El par se guarda ordenado (transaction_a < transaction_b).
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS duplicate_dismissals (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_a UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    transaction_b UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (transaction_a, transaction_b),
+    CHECK (transaction_a < transaction_b)
+);
+
+CREATE INDEX IF NOT EXISTS idx_duplicate_dismissals_user ON duplicate_dismissals(user_id);
+
+-- Para buscar candidatos: mismo usuario y mismo monto
+CREATE INDEX IF NOT EXISTS idx_transactions_user_amount ON transactions(user_id, amount);
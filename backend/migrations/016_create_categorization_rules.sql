@@ -0,0 +1,27 @@
+-- ============================================
+-- Migración 016: Reglas de categorización automática
+-- Una regla asigna una categoría a las transacciones cuya descripción coincide
+-- (contiene un texto o cumple una expresión regular), opcionalmente dentro de un
+-- rango de monto. Se evalúan por prioridad: menor número = se evalúa primero.
+-- Ejemplo: "descripción contiene 'uber' → Transporte"
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS categorization_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    match_type VARCHAR(10) NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'regex')),
+    pattern VARCHAR(255) NOT NULL,
+    min_amount DECIMAL(15, 2) CHECK (min_amount >= 0),
+    max_amount DECIMAL(15, 2) CHECK (max_amount > 0),
+    -- Tipo de transacción al que aplica; siempre coincide con el tipo de la categoría
+    type VARCHAR(10) NOT NULL CHECK (type IN ('income', 'expense')),
+    priority INTEGER NOT NULL DEFAULT 100,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (min_amount IS NULL OR max_amount IS NULL OR min_amount <= max_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categorization_rules_user ON categorization_rules(user_id, priority);
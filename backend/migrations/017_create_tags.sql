@@ -0,0 +1,23 @@
+-- ============================================
+-- Migración 017: Etiquetas de transacciones
+-- A diferencia de la categoría (una sola por transacción), una transacción puede
+-- tener varias etiquetas transversales: "viaje-cartagena", "reembolsable", "trabajo".
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#6b7280',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    -- Los nombres se guardan normalizados (minúsculas, guiones): uno por usuario
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag ON transaction_tags(tag_id);
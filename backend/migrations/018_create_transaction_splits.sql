@@ -0,0 +1,29 @@
+-- ============================================
+-- Migración 018: Transacciones divididas
+-- Un mismo recibo (ej: el mercado) puede repartirse entre varias categorías.
+-- Cada línea tiene su categoría, monto y nota; los montos suman el de la transacción.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction ON transaction_splits(transaction_id, position);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category ON transaction_splits(category_id);
+
+-- transaction_lines: una fila por línea de cada transacción. Las divididas aportan
+-- una fila por división; el resto, una fila con su propia categoría y monto.
+-- Los reportes y presupuestos agregan sobre esta vista en vez de sobre transactions.
+CREATE OR REPLACE VIEW transaction_lines AS
+SELECT t.id AS transaction_id, t.user_id, s.category_id, s.amount, t.type, t.transfer_kind, t.date
+FROM transactions t
+JOIN transaction_splits s ON s.transaction_id = t.id
+UNION ALL
+SELECT t.id, t.user_id, t.category_id, t.amount, t.type, t.transfer_kind, t.date
+FROM transactions t
+WHERE NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id);
@@ -0,0 +1,67 @@
+-- ============================================
+-- Migración 019: Búsqueda de texto completo en transacciones
+-- Cada transacción guarda un tsvector con su descripción (peso A) y el nombre y
+-- alias de su categoría (peso B). La configuración es_unaccent es la de español
+-- pero sin tildes: "credito" encuentra "crédito".
+-- ============================================
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'es_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION es_unaccent (COPY = pg_catalog.spanish);
+        ALTER TEXT SEARCH CONFIGURATION es_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+    END IF;
+END $$;
+
+-- Vector de búsqueda de una transacción a partir de su descripción y categoría
+CREATE OR REPLACE FUNCTION transaction_search_vector(p_description TEXT, p_category_id UUID)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('es_unaccent', COALESCE(p_description, '')), 'A')
+        || setweight(to_tsvector('es_unaccent', COALESCE(
+               (SELECT name || ' ' || nickname FROM categories WHERE id = p_category_id), ''
+           )), 'B')
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Mantener el vector al crear o editar la transacción
+CREATE OR REPLACE FUNCTION transactions_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := transaction_search_vector(NEW.description, NEW.category_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_transactions_search_vector ON transactions;
+CREATE TRIGGER trg_transactions_search_vector
+    BEFORE INSERT OR UPDATE OF description, category_id ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_search_vector_update();
+
+-- Al renombrar una categoría (o cambiar su alias) se actualizan sus transacciones
+CREATE OR REPLACE FUNCTION categories_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE transactions
+    SET search_vector = transaction_search_vector(description, category_id)
+    WHERE category_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories;
+CREATE TRIGGER trg_categories_search_vector
+    AFTER UPDATE OF name, nickname ON categories
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name OR OLD.nickname IS DISTINCT FROM NEW.nickname)
+    EXECUTE FUNCTION categories_search_vector_update();
+
+-- Llenar las transacciones que existían antes de esta migración
+UPDATE transactions
+SET search_vector = transaction_search_vector(description, category_id)
+WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_search ON transactions USING GIN(search_vector);
@@ -0,0 +1,9 @@
+-- ============================================
+-- Migración 020: Índice para paginación por cursor
+-- El listado ordena por (date, created_at, id) y la página siguiente se pide como
+-- "después de esta fila": con este índice PostgreSQL salta directo a la posición
+-- en vez de recorrer y descartar las filas anteriores como hace OFFSET.
+-- ============================================
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_keyset
+ON transactions(user_id, date DESC, created_at DESC, id DESC);
@@ -0,0 +1,16 @@
+-- ============================================
+-- Migración 021: Vistas guardadas
+-- Una vista es un conjunto de filtros de transacciones con nombre. Los filtros se
+-- guardan como JSON y las fechas pueden ser relativas ("this_month", "last_90_days"):
+-- se calculan cada vez que se usa la vista.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS saved_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
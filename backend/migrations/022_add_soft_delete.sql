@@ -0,0 +1,31 @@
+-- ============================================
+-- Migración 022: Papelera (borrado lógico)
+-- Borrar una transacción, categoría, cuenta de ahorro o presupuesto solo marca
+-- deleted_at. Las filas marcadas no aparecen en ninguna lectura, se pueden
+-- restaurar desde la papelera y un proceso en segundo plano las elimina del todo
+-- cuando pasan los días de retención.
+-- ============================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE savings_accounts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- La papelera y la purga solo leen filas borradas: índices parciales pequeños
+CREATE INDEX IF NOT EXISTS idx_transactions_trash ON transactions(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_trash ON categories(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_savings_accounts_trash ON savings_accounts(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_budgets_trash ON budgets(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Reportes y presupuestos agregan sobre transaction_lines: las transacciones
+-- en la papelera no suman
+CREATE OR REPLACE VIEW transaction_lines AS
+SELECT t.id AS transaction_id, t.user_id, s.category_id, s.amount, t.type, t.transfer_kind, t.date
+FROM transactions t
+JOIN transaction_splits s ON s.transaction_id = t.id
+WHERE t.deleted_at IS NULL
+UNION ALL
+SELECT t.id, t.user_id, t.category_id, t.amount, t.type, t.transfer_kind, t.date
+FROM transactions t
+WHERE t.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id);
@@ -0,0 +1,7 @@
+-- ============================================
+-- Migración 023: Categorías archivadas
+-- Una categoría archivada ya no se ofrece al registrar transacciones, pero sus
+-- transacciones, presupuestos y reportes históricos siguen intactos.
+-- ============================================
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE;
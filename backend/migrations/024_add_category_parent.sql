@@ -0,0 +1,23 @@
+-- ============================================
+-- Migración 024: Subcategorías
+-- Una categoría puede tener una categoría padre del mismo tipo (ej: Comida →
+-- Restaurante, Mercado). Los presupuestos de un padre cuentan el gasto de todas
+-- sus subcategorías y los reportes pueden agrupar los totales en el padre.
+-- ============================================
+
+ALTER TABLE categories
+ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id) WHERE parent_id IS NOT NULL;
+
+-- category_descendants: la categoría y todas sus subcategorías, a cualquier profundidad.
+-- UNION (no UNION ALL) evita un ciclo infinito aunque el árbol quedara mal armado.
+CREATE OR REPLACE FUNCTION category_descendants(p_id UUID)
+RETURNS TABLE(id UUID) AS $$
+    WITH RECURSIVE tree AS (
+        SELECT c.id FROM categories c WHERE c.id = p_id
+        UNION
+        SELECT c.id FROM categories c JOIN tree ON c.parent_id = tree.id
+    )
+    SELECT tree.id FROM tree
+$$ LANGUAGE sql STABLE;
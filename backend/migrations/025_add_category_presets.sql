@@ -0,0 +1,17 @@
+-- ============================================
+-- Migración 025: Paquetes de categorías
+-- Las categorías predeterminadas vienen de paquetes versionados (internal/presets).
+-- preset_key guarda de qué categoría de paquete salió cada una, para que volver a
+-- aplicar un paquete no la duplique aunque el usuario la haya renombrado.
+-- ============================================
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS preset_key VARCHAR(50);
+
+-- Qué paquetes aplicó cada usuario y en qué versión
+CREATE TABLE IF NOT EXISTS category_preset_applications (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    preset_id VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, preset_id)
+);
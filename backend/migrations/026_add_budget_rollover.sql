@@ -0,0 +1,12 @@
+-- ============================================
+-- Migración 026: Arrastre de presupuestos (rollover)
+-- Cada presupuesto decide qué pasa al mes siguiente de la misma categoría:
+--   none                → nada, cada mes empieza de cero
+--   surplus             → lo que sobró se suma al disponible del mes siguiente
+--   surplus_and_deficit → también lo que se pasó se resta del mes siguiente
+-- El monto arrastrado no se guarda: se calcula al leer, así que editar una
+-- transacción de un mes anterior cambia el arrastre de los meses siguientes.
+-- ============================================
+
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS rollover VARCHAR(20) NOT NULL DEFAULT 'none'
+    CHECK (rollover IN ('none', 'surplus', 'surplus_and_deficit'));
@@ -0,0 +1,30 @@
+-- ============================================
+-- Migración 027: Plantillas de presupuestos y copia automática
+-- Una plantilla es un conjunto de presupuestos con nombre (categoría, límite y
+-- modo de arrastre) que se puede aplicar a cualquier mes. Los ítems se guardan
+-- como JSON: al aplicarla se ignoran las categorías que ya no existen.
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS budget_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    items JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
+
+-- Copia automática: el día 1 de cada mes se crean los presupuestos del mes a
+-- partir de los del mes anterior, para los usuarios que la activaron
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auto_copy_budgets BOOLEAN NOT NULL DEFAULT false;
+
+-- Meses que ya se copiaron (o que no se deben copiar) por usuario, para que el
+-- worker no repita la copia ni recree presupuestos que el usuario borró
+CREATE TABLE IF NOT EXISTS budget_auto_copies (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    month INTEGER NOT NULL CHECK (month BETWEEN 1 AND 12),
+    year INTEGER NOT NULL,
+    copied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, year, month)
+);
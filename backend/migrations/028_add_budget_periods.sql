@@ -0,0 +1,42 @@
+-- ============================================
+-- Migración 028: Periodos de presupuesto
+-- Un presupuesto ya no es siempre mensual: tiene un tipo de periodo (weekly,
+-- monthly, quarterly, yearly o custom) y un rango de fechas [start_date, end_date].
+-- Los presupuestos existentes pasan a ser mensuales del mes que tenían.
+--
+-- month y year dejan de usarse y quedan en NULL. No se borran porque la
+-- migración 004, que corre en cada arranque, crea un índice sobre ellas.
+-- ============================================
+
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS period_type VARCHAR(20) NOT NULL DEFAULT 'monthly'
+    CHECK (period_type IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom'));
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS start_date DATE;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS end_date DATE;
+
+ALTER TABLE budgets ALTER COLUMN month DROP NOT NULL;
+ALTER TABLE budgets ALTER COLUMN year DROP NOT NULL;
+
+UPDATE budgets
+SET start_date = make_date(year, month, 1),
+    end_date = (make_date(year, month, 1) + INTERVAL '1 month' - INTERVAL '1 day')::date,
+    month = NULL,
+    year = NULL
+WHERE start_date IS NULL;
+
+ALTER TABLE budgets ALTER COLUMN start_date SET NOT NULL;
+ALTER TABLE budgets ALTER COLUMN end_date SET NOT NULL;
+
+-- El único por mes se reemplaza por uno por tipo de periodo y fecha de inicio
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_user_id_category_id_month_year_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_category_period
+    ON budgets(user_id, category_id, period_type, start_date);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'budgets_period_check') THEN
+        ALTER TABLE budgets ADD CONSTRAINT budgets_period_check CHECK (end_date >= start_date);
+    END IF;
+END $$;
+
+-- Presupuestos activos en una fecha
+CREATE INDEX IF NOT EXISTS idx_budgets_user_dates ON budgets(user_id, start_date, end_date);
@@ -0,0 +1,34 @@
+-- ============================================
+-- Migración 029: Fecha de los movimientos de ahorro
+-- created_at es cuándo se registró el movimiento; occurred_on es el día al que
+-- corresponde. Una transferencia con fecha pasada mueve el saldo en su fecha, no
+-- en el día en que se cargó. El historial, los filtros y los aportes a la meta
+-- se calculan sobre occurred_on.
+-- ============================================
+
+ALTER TABLE savings_movements ADD COLUMN IF NOT EXISTS occurred_on DATE;
+
+-- Movimientos existentes: las transferencias toman la fecha de su transacción y el
+-- resto el día en que se registraron. La tabla es inmutable, así que el trigger se
+-- apaga solo mientras se completa la columna.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM savings_movements WHERE occurred_on IS NULL) THEN
+        ALTER TABLE savings_movements DISABLE TRIGGER trg_savings_movements_immutable;
+
+        UPDATE savings_movements sm
+        SET occurred_on = COALESCE(
+            (SELECT t.date FROM transactions t
+             WHERE t.id = sm.transaction_id AND sm.type IN ('transfer_in', 'transfer_out')),
+            sm.created_at::date
+        )
+        WHERE sm.occurred_on IS NULL;
+
+        ALTER TABLE savings_movements ENABLE TRIGGER trg_savings_movements_immutable;
+    END IF;
+END $$;
+
+ALTER TABLE savings_movements ALTER COLUMN occurred_on SET DEFAULT CURRENT_DATE;
+ALTER TABLE savings_movements ALTER COLUMN occurred_on SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_savings_movements_account_date ON savings_movements(account_id, occurred_on);
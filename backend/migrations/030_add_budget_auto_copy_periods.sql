@@ -0,0 +1,47 @@
+-- ============================================
+-- Migración 030: Copia automática por tipo de periodo
+-- La copia automática ya no es solo mensual: al empezar cada semana, mes,
+-- trimestre o año se copian los presupuestos de ese tipo del periodo anterior.
+-- budget_auto_copies pasa a registrar el tipo de periodo y su fecha de inicio;
+-- las marcas existentes quedan como mensuales.
+--
+-- month y year dejan de usarse y quedan en NULL (la migración 027, que corre en
+-- cada arranque, sigue creando la tabla con ellas).
+-- ============================================
+
+ALTER TABLE budget_auto_copies ADD COLUMN IF NOT EXISTS period_type VARCHAR(20) NOT NULL DEFAULT 'monthly'
+    CHECK (period_type IN ('weekly', 'monthly', 'quarterly', 'yearly'));
+ALTER TABLE budget_auto_copies ADD COLUMN IF NOT EXISTS start_date DATE;
+
+-- La clave (user_id, year, month) se reemplaza por (user_id, period_type, start_date)
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_constraint c
+        JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY(c.conkey)
+        WHERE c.conrelid = 'budget_auto_copies'::regclass AND c.contype = 'p' AND a.attname = 'month'
+    ) THEN
+        ALTER TABLE budget_auto_copies DROP CONSTRAINT budget_auto_copies_pkey;
+    END IF;
+END $$;
+
+ALTER TABLE budget_auto_copies ALTER COLUMN month DROP NOT NULL;
+ALTER TABLE budget_auto_copies ALTER COLUMN year DROP NOT NULL;
+
+UPDATE budget_auto_copies
+SET start_date = make_date(year, month, 1),
+    month = NULL,
+    year = NULL
+WHERE start_date IS NULL;
+
+ALTER TABLE budget_auto_copies ALTER COLUMN start_date SET NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'budget_auto_copies'::regclass AND contype = 'p'
+    ) THEN
+        ALTER TABLE budget_auto_copies ADD PRIMARY KEY (user_id, period_type, start_date);
+    END IF;
+END $$;
@@ -0,0 +1,9 @@
+-- ============================================
+-- Migración 031: Deshacer un import sin perder sus IDs externos
+-- Al deshacer un import, sus transacciones van a la papelera con su external_id y
+-- el lote queda marcado con deleted_at en vez de borrarse. Así, si se vuelve a
+-- importar el mismo extracto, esas filas se reemplazan en lugar de duplicarse, y
+-- una transacción restaurada de la papelera sigue bloqueando su reimportación.
+-- ============================================
+
+ALTER TABLE import_batches ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,73 @@
+-- ============================================
+-- Benchmark: paginación por OFFSET vs por cursor (keyset) en GET /api/transactions
+--
+-- Crea un usuario de prueba con 100.000 transacciones, compara el plan y el tiempo de
+-- leer una página profunda con cada método y el costo del COUNT(*), y al final deshace
+-- todo (ROLLBACK): no deja datos en la base.
+--
+-- Uso (con las migraciones ya aplicadas):
+--   psql "$DATABASE_URL" -f scripts/bench_pagination.sql
+--
+-- Qué mirar en la salida de EXPLAIN ANALYZE:
+--   - OFFSET lee y descarta todas las filas anteriores a la página: el tiempo crece
+--     con el número de página.
+--   - El cursor entra al índice idx_transactions_user_keyset justo en la posición:
+--     el tiempo es el mismo en la página 1 que en la 2.500.
+--   - COUNT(*) recorre todas las filas del usuario en cada request; por eso el
+--     modo cursor no lo calcula salvo con include_total=true.
+-- ============================================
+
+\timing on
+
+BEGIN;
+
+INSERT INTO users (id, email, password_hash, name)
+VALUES ('00000000-0000-0000-0000-0000000be7c4', 'bench-paginacion@example.com', 'x', 'Bench');
+
+INSERT INTO categories (id, user_id, name, type, color, icon)
+VALUES ('00000000-0000-0000-0000-0000000ca7e1', '00000000-0000-0000-0000-0000000be7c4', 'Mercado', 'expense', '#10b981', 'shopping-cart');
+
+-- 100.000 gastos repartidos en ~5 años, varios por día
+INSERT INTO transactions (user_id, category_id, amount, type, description, date, created_at)
+SELECT '00000000-0000-0000-0000-0000000be7c4',
+       '00000000-0000-0000-0000-0000000ca7e1',
+       (random() * 200000 + 1000)::numeric(15, 2),
+       'expense',
+       'Compra ' || g,
+       DATE '2021-01-01' + (g % 1825),
+       TIMESTAMPTZ '2021-01-01' + g * INTERVAL '1 minute'
+FROM generate_series(1, 100000) AS g;
+
+ANALYZE transactions;
+
+-- 1) Página 2.500 (limit 20) con OFFSET, como en ?page=2500&limit=20
+EXPLAIN (ANALYZE, BUFFERS)
+SELECT t.id, t.date, t.created_at, t.amount, t.description
+FROM transactions t
+WHERE t.user_id = '00000000-0000-0000-0000-0000000be7c4'
+ORDER BY t.date DESC, t.created_at DESC, t.id DESC
+LIMIT 21 OFFSET 49980;
+
+-- 2) La misma página con cursor: se parte de la última fila de la página anterior
+SELECT t.date AS cursor_date, t.created_at AS cursor_created_at, t.id AS cursor_id
+FROM transactions t
+WHERE t.user_id = '00000000-0000-0000-0000-0000000be7c4'
+ORDER BY t.date DESC, t.created_at DESC, t.id DESC
+LIMIT 1 OFFSET 49979
+\gset
+
+EXPLAIN (ANALYZE, BUFFERS)
+SELECT t.id, t.date, t.created_at, t.amount, t.description
+FROM transactions t
+WHERE t.user_id = '00000000-0000-0000-0000-0000000be7c4'
+  AND (t.date, t.created_at, t.id) < (:'cursor_date'::date, :'cursor_created_at'::timestamptz, :'cursor_id'::uuid)
+ORDER BY t.date DESC, t.created_at DESC, t.id DESC
+LIMIT 21;
+
+-- 3) El total que el modo page/limit calcula en cada request
+EXPLAIN (ANALYZE, BUFFERS)
+SELECT COUNT(*)
+FROM transactions t
+WHERE t.user_id = '00000000-0000-0000-0000-0000000be7c4';
+
+ROLLBACK;
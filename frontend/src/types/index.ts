@@ -95,17 +95,35 @@ export interface Budget {
   category_icon?: string;
   amount_limit: number;
   spent: number;
-  month: number;
-  year: number;
+  rollover: BudgetRollover;
+  carried: number; // Arrastrado de los periodos anteriores (negativo = se pasó)
+  available: number; // amount_limit + carried
+  period_type: BudgetPeriodType;
+  start_date: string;
+  end_date: string; // Incluido
   created_at: string;
   updated_at: string;
 }
 
+export type BudgetPeriodType =
+  | "weekly"
+  | "monthly"
+  | "quarterly"
+  | "yearly"
+  | "custom";
+
+export type BudgetRollover = "none" | "surplus" | "surplus_and_deficit";
+
+// Sin start_date se usan month y year (presupuesto mensual)
 export interface CreateBudgetRequest {
   category_id: string;
   amount_limit: number;
-  month: number;
-  year: number;
+  period_type?: BudgetPeriodType;
+  start_date?: string;
+  end_date?: string; // Solo para custom
+  month?: number;
+  year?: number;
+  rollover?: BudgetRollover;
 }
 
 export interface CategorySummary {